@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/internal/catalog"
+	"github.com/goldenfealla/gear-manager/usecase"
+)
+
+const usage = `usage:
+  gear-manager                      start the server
+  gear-manager import [flags] FILE  import a catalog, FILE - reads stdin
+  gear-manager export [flags]       write the catalog to stdout
+
+run a command with -h for its flags`
+
+// runCommand runs the command of args, the commands share the setup of the
+// server but don't serve
+func runCommand(ctx context.Context, args []string, gu *usecase.GearUsecase, v *validator.Validate) error {
+	switch args[0] {
+	case "import":
+		return runImport(ctx, args[1:], gu, v)
+	case "export":
+		return runExport(ctx, args[1:], gu)
+	}
+
+	return fmt.Errorf("unknown command %v\n%v", args[0], usage)
+}
+
+func runImport(ctx context.Context, args []string, gu *usecase.GearUsecase, v *validator.Validate) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "csv", "csv or jsonl")
+	dryRun := flags.Bool("dry-run", false, "check every row without writing")
+	reason := flags.String("reason", "import", "reason recorded in the gear history")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import takes one FILE\n%v", usage)
+	}
+
+	f, err := catalog.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+
+	if name := flags.Arg(0); name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	ctx = domain.WithGearChange(ctx, &domain.GearChange{Reason: *reason})
+
+	rows, err := catalog.ReadRows(ctx, r, f, v)
+	if err != nil {
+		return err
+	}
+
+	report, err := gu.ImportGear(ctx, rows, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+
+	err = out.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%v rows have errors, nothing was imported", len(report.Errors))
+	}
+
+	return nil
+}
+
+func runExport(ctx context.Context, args []string, gu *usecase.GearUsecase) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "csv", "csv or jsonl")
+	category := flags.String("category", domain.AllCategory, "category key")
+	query := flags.String("q", "", "search query")
+	archived := flags.Bool("archived", false, "export the archived gear instead")
+	flags.Parse(args)
+
+	f, err := catalog.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	filter := domain.ListGearFilter{
+		Category: category,
+		Archived: *archived,
+	}
+
+	if *query != "" {
+		filter.Query = query
+	}
+
+	w := catalog.NewWriter(os.Stdout, f)
+
+	err = gu.ExportGear(ctx, filter, w.Write)
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
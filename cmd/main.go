@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -20,6 +23,8 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/goldenfealla/gear-manager/config"
+	image "github.com/goldenfealla/gear-manager/internal/file"
+	appmiddleware "github.com/goldenfealla/gear-manager/internal/middleware"
 	"github.com/goldenfealla/gear-manager/internal/repository/postgres"
 	"github.com/goldenfealla/gear-manager/internal/rest"
 	"github.com/goldenfealla/gear-manager/internal/validation"
@@ -38,42 +43,6 @@ func main() {
 	}
 	defer pool.Close()
 
-	// Connect to database Redis
-	log.Println("Connecting to Redis")
-	ropts, err := redis.ParseURL(c.Redis)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	rdb := redis.NewClient(ropts)
-
-	// init Session Store
-	store, err := redisstore.NewRedisStore(context.Background(), rdb)
-	if err != nil {
-		log.Fatal("failed to create redis store: ", err)
-	}
-
-	// init S3 storage
-	cfg, err := s3config.LoadDefaultConfig(context.TODO(),
-		s3config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(
-				c.S3.AccountKeyID,
-				c.S3.AccountKeySecret,
-				"",
-			)),
-		s3config.WithRegion("auto"),
-	)
-
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	log.Println("Connecting to R2 Cloudflare")
-	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(
-			fmt.Sprintf("https://%s.r2.cloudflarestorage.com", c.S3.AccountID),
-		)
-	})
-
 	e := echo.New()
 
 	// Middleware
@@ -87,7 +56,10 @@ func main() {
 		MaxAge:           3600,
 	}))
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
-		Skipper:      middleware.DefaultSkipper,
+		// the catalog import and export take as long as the catalog is big
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/gear/import" || c.Path() == "/gear/export"
+		},
 		ErrorMessage: "Request timeout",
 		OnTimeoutRouteErrorHandler: func(err error, c echo.Context) {
 			log.Println(c.Path())
@@ -100,32 +72,139 @@ func main() {
 		Output:           e.Logger.Output(),
 	}))
 	e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(rate.Limit(20))))
-	e.Use(session.Middleware(store))
 
-	// set up validator
-	v := validator.New(validator.WithRequiredStructEnabled())
-	v.RegisterValidation("is-gear", validation.ValidateIsGear)
+	// init object storage
+	storage, err := newStorage(c, e)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	renditions := image.DefaultRenditions
+
+	if c.ImageRenditions != nil {
+		renditions = []image.Rendition{}
+
+		for _, r := range c.ImageRenditions {
+			renditions = append(renditions, image.Rendition{
+				Name:  r.Name,
+				Width: r.Width,
+			})
+		}
+	}
 
 	// Build Repository
-	gr := postgres.NewGearRepository(pool, s3Client)
+	cr := postgres.NewCategoryRepository(pool)
+	gr := postgres.NewGearRepository(pool, storage, cr, renditions)
 	ur := postgres.NewUserRepository(pool)
 	ar := postgres.NewAddressRepository(pool)
 	or := postgres.NewOrderRepository(pool)
+	br := postgres.NewBuildRepository(pool)
+	pr := postgres.NewPromotionRepository(pool)
+	tx := postgres.NewTransactor(pool)
+
+	// set up validator
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterValidationCtx("is-gear", validation.ValidateIsGear(cr))
 
 	// Build Usecase
-	gu := usecase.NewGearUsecase(gr)
+	gu := usecase.NewGearUsecase(gr, cr, tx)
+	cu := usecase.NewCategoryUsecase(cr)
 	uu := usecase.NewUserUsecase(ur)
 	au := usecase.NewAddressUsecase(ar)
-	ou := usecase.NewOrderUsercase(or, ur, gr)
+	ou := usecase.NewOrderUsercase(or, ur, gr, tx)
+	bu := usecase.NewBuildUsecase(br, or, gr, cr, tx)
+	pu := usecase.NewPromotionUsecase(pr, gr, cr)
+
+	// a command given as argument runs instead of the server
+	if len(os.Args) > 1 {
+		err = runCommand(context.Background(), os.Args[1:], gu, v)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	// Connect to database Redis
+	log.Println("Connecting to Redis")
+	ropts, err := redis.ParseURL(c.Redis)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	rdb := redis.NewClient(ropts)
+
+	// init Session Store
+	store, err := redisstore.NewRedisStore(context.Background(), rdb)
+	if err != nil {
+		log.Fatal("failed to create redis store: ", err)
+	}
+
+	e.Use(session.Middleware(store))
+	e.Use(appmiddleware.SessionUser(ur))
 
 	// Build Handler
 	rest.NewUserHandler(e, uu, v)
 	rest.NewGearHandler(e, gu, v)
+	rest.NewCategoryHandler(e, cu, v)
 	rest.NewAddressHandler(e, au, v)
 	rest.NewOrderHandler(e, ou, v)
+	rest.NewBuildHandler(e, bu, v)
+	rest.NewPromotionHandler(e, pu, v)
 
 	err = e.Start(fmt.Sprintf("%v:%v", c.Host, c.Port))
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
+
+// newStorage builds the storage of the configured driver, the local and memory
+// storages are served by e under /storage
+func newStorage(c *config.Config, e *echo.Echo) (image.Storage, error) {
+	switch c.Storage.Driver {
+	case config.StorageLocal:
+		secret := []byte(c.Storage.Secret)
+
+		if len(secret) == 0 {
+			log.Println("env STORAGE_SECRET not found, signed URLs won't survive a restart")
+			secret = make([]byte, 32)
+			rand.Read(secret)
+		}
+
+		log.Println("Using local storage in", c.Storage.Dir)
+		storage, err := image.NewLocalStorage(c.Storage.Dir, c.Storage.BaseURL, secret)
+		if err != nil {
+			return nil, err
+		}
+
+		e.GET("/storage/*", echo.WrapHandler(http.StripPrefix("/storage", storage)))
+
+		return storage, nil
+	case config.StorageMemory:
+		log.Println("Using in-memory storage, uploaded images are lost on exit")
+		storage := image.NewMemoryStorage(c.Storage.BaseURL)
+
+		e.GET("/storage/*", echo.WrapHandler(http.StripPrefix("/storage", storage)))
+
+		return storage, nil
+	}
+
+	cfg, err := s3config.LoadDefaultConfig(context.TODO(),
+		s3config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(
+				c.S3.AccountKeyID,
+				c.S3.AccountKeySecret,
+				"",
+			)),
+		s3config.WithRegion("auto"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Connecting to R2 Cloudflare")
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(c.S3.Endpoint)
+	})
+
+	return image.NewS3Storage(s3Client, c.S3.Bucket, c.Storage.BaseURL), nil
+}
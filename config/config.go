@@ -10,6 +10,7 @@ to load the config
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -20,15 +21,49 @@ import (
 )
 
 const (
-	defaultHost    = "0.0.0.0"
-	defaultPort    = "8080"
-	defaultTimeout = 30
+	defaultHost       = "0.0.0.0"
+	defaultPort       = "8080"
+	defaultTimeout    = 30
+	defaultStorageDir = "storage"
+	defaultS3Bucket   = "gear-ecommerce"
+	defaultS3URL      = "https://image-gear-ecommerce.goldenfealla.dev"
 )
 
+// Storage drivers, see StorageConfig
+const (
+	StorageS3     = "s3"
+	StorageLocal  = "local"
+	StorageMemory = "memory"
+)
+
+// S3Config is only loaded for the StorageS3 driver, Config.S3 is nil
+// otherwise
 type S3Config struct {
 	AccountID        string
 	AccountKeyID     string
 	AccountKeySecret string
+	Bucket           string
+	// Endpoint defaults to the Cloudflare R2 endpoint of AccountID
+	Endpoint string
+}
+
+// StorageConfig chooses where the uploaded images are kept
+type StorageConfig struct {
+	// Driver is one of StorageS3, StorageLocal or StorageMemory
+	Driver string
+	// Dir is where the local driver writes the objects
+	Dir string
+	// BaseURL is where the objects are served from, the local and memory
+	// drivers serve them under /storage of this service
+	BaseURL string
+	// Secret signs the signed URLs of the local driver
+	Secret string
+}
+
+// RenditionConfig is one resized copy made of every uploaded image
+type RenditionConfig struct {
+	Name  string
+	Width int
 }
 
 type Config struct {
@@ -37,8 +72,12 @@ type Config struct {
 	Postgres     string
 	Redis        string
 	Timeout      time.Duration
+	Storage      *StorageConfig
 	S3           *S3Config
 	AllowOrigins []string
+	// ImageRenditions is nil when the env is not set, the default
+	// renditions are used then
+	ImageRenditions []RenditionConfig
 }
 
 // No need to return error when you can't load the config
@@ -97,33 +136,96 @@ func Load() *Config {
 
 	}
 
-	S3AccountID := os.Getenv("S3_ACCOUNT_ID")
-	S3AccountKeyID := os.Getenv("S3_ACCOUNT_KEY_ID")
-	S3AccountKeySecret := os.Getenv("S3_ACCOUNT_KEY_SECRET")
+	storageDriver := os.Getenv("STORAGE")
+
+	if storageDriver == "" {
+		storageDriver = StorageS3
+	}
 
-	if S3AccountID == "" {
-		log.Fatalln("S3_ACCOUNT_ID not found, Please add one")
+	storageConfig := &StorageConfig{
+		Driver:  storageDriver,
+		Dir:     os.Getenv("STORAGE_DIR"),
+		BaseURL: os.Getenv("STORAGE_URL"),
+		Secret:  os.Getenv("STORAGE_SECRET"),
 	}
 
-	if S3AccountKeyID == "" {
-		log.Fatalln("S3_ACCOUNT_KEY_ID not found, Please add one")
+	if storageConfig.Dir == "" {
+		storageConfig.Dir = defaultStorageDir
 	}
 
-	if S3AccountKeySecret == "" {
-		log.Fatalln("S3_ACCOUNT_KEY_SECRET not found, Please add one")
+	var s3Config *S3Config
+
+	switch storageDriver {
+	case StorageS3:
+		s3Config = &S3Config{
+			AccountID:        os.Getenv("S3_ACCOUNT_ID"),
+			AccountKeyID:     os.Getenv("S3_ACCOUNT_KEY_ID"),
+			AccountKeySecret: os.Getenv("S3_ACCOUNT_KEY_SECRET"),
+			Bucket:           os.Getenv("S3_BUCKET"),
+			Endpoint:         os.Getenv("S3_ENDPOINT"),
+		}
+
+		if s3Config.AccountID == "" && s3Config.Endpoint == "" {
+			log.Fatalln("S3_ACCOUNT_ID not found, Please add one")
+		}
+
+		if s3Config.AccountKeyID == "" {
+			log.Fatalln("S3_ACCOUNT_KEY_ID not found, Please add one")
+		}
+
+		if s3Config.AccountKeySecret == "" {
+			log.Fatalln("S3_ACCOUNT_KEY_SECRET not found, Please add one")
+		}
+
+		if s3Config.Bucket == "" {
+			s3Config.Bucket = defaultS3Bucket
+		}
+
+		if s3Config.Endpoint == "" {
+			s3Config.Endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", s3Config.AccountID)
+		}
+
+		if storageConfig.BaseURL == "" {
+			storageConfig.BaseURL = defaultS3URL
+		}
+	case StorageLocal, StorageMemory:
+		if storageConfig.BaseURL == "" {
+			storageConfig.BaseURL = fmt.Sprintf("http://localhost:%v/storage", portEnv)
+		}
+	default:
+		log.Fatalf("invalid STORAGE %q, expected %v, %v or %v\n", storageDriver, StorageS3, StorageLocal, StorageMemory)
+	}
+
+	// IMAGE_RENDITIONS is formatted as "thumbnail:160;card:400;..."
+	var imageRenditions []RenditionConfig
+
+	imageRenditionsStr := os.Getenv("IMAGE_RENDITIONS")
+
+	if imageRenditionsStr != "" {
+		for _, v := range strings.Split(imageRenditionsStr, ";") {
+			name, widthStr, _ := strings.Cut(v, ":")
+			width, err := strconv.Atoi(widthStr)
+
+			if name == "" || err != nil || width <= 0 {
+				log.Fatalf("invalid IMAGE_RENDITIONS entry %q, expected name:width\n", v)
+			}
+
+			imageRenditions = append(imageRenditions, RenditionConfig{
+				Name:  name,
+				Width: width,
+			})
+		}
 	}
 
 	return &Config{
-		Host:     hostEnv,
-		Port:     portEnv,
-		Postgres: posgreSQLEnv,
-		Redis:    RedisEnv,
-		Timeout:  timeoutContext,
-		S3: &S3Config{
-			AccountID:        S3AccountID,
-			AccountKeyID:     S3AccountKeyID,
-			AccountKeySecret: S3AccountKeySecret,
-		},
-		AllowOrigins: allowOrigins,
+		Host:            hostEnv,
+		Port:            portEnv,
+		Postgres:        posgreSQLEnv,
+		Redis:           RedisEnv,
+		Timeout:         timeoutContext,
+		Storage:         storageConfig,
+		S3:              s3Config,
+		AllowOrigins:    allowOrigins,
+		ImageRenditions: imageRenditions,
 	}
 }
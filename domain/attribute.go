@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeEnum    AttributeType = "enum"
+)
+
+// Attribute is one technical specification of the gear of a category, e.g.
+// the socket of a CPU. Subcategories inherit the attributes of their parents.
+type Attribute struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	CategoryID uuid.UUID     `json:"category_id" db:"category_id"`
+	Key        string        `json:"key" db:"key"`
+	Name       string        `json:"name" db:"name"`
+	Type       AttributeType `json:"type" db:"type"`
+	Unit       *string       `json:"unit,omitempty" db:"unit"`
+	Options    []string      `json:"options,omitempty" db:"options"`
+	Required   bool          `json:"required" db:"required"`
+	Position   int64         `json:"position" db:"position"`
+}
+
+// Specs are the attribute values of a gear keyed by attribute key
+type Specs map[string]any
+
+type AddAttributeForm struct {
+	Key      string        `json:"key"      conform:"trim,lower" validate:"required,gte=2,lte=32,excludes= ,excludes=."`
+	Name     string        `json:"name"     conform:"trim"       validate:"required,lte=64"`
+	Type     AttributeType `json:"type"     conform:"trim,lower" validate:"required,oneof=string number boolean enum"`
+	Unit     *string       `json:"unit"     conform:"trim"       validate:"omitempty,lte=16"`
+	Options  []string      `json:"options"                       validate:"required_if=Type enum,dive,required"`
+	Required bool          `json:"required"`
+	Position int64         `json:"position"`
+}
+
+type UpdateAttributeForm struct {
+	Name     *string   `json:"name,omitempty"     db:"name"     conform:"trim" validate:"omitempty,lte=64"`
+	Unit     *string   `json:"unit,omitempty"     db:"unit"     conform:"trim" validate:"omitempty,lte=16"`
+	Options  *[]string `json:"options,omitempty"  db:"options"                 validate:"omitempty,dive,required"`
+	Required *bool     `json:"required,omitempty" db:"required"                validate:"omitempty"`
+	Position *int64    `json:"position,omitempty" db:"position"                validate:"omitempty"`
+}
+
+// ValidateSpecs checks specs against the attributes of the gear's category
+// and returns them normalized, a nil value drops the attribute
+func ValidateSpecs(attributes []*Attribute, specs Specs) (Specs, error) {
+	result := Specs{}
+	known := make(map[string]bool, len(attributes))
+
+	for _, a := range attributes {
+		known[a.Key] = true
+
+		v, ok := specs[a.Key]
+
+		if !ok || v == nil {
+			if a.Required {
+				return nil, &SpecError{Attribute: a.Key, Message: "is required"}
+			}
+
+			continue
+		}
+
+		v, err := a.normalize(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result[a.Key] = v
+	}
+
+	for key := range specs {
+		if !known[key] {
+			return nil, &SpecError{Attribute: key, Message: "is not an attribute of this category"}
+		}
+	}
+
+	return result, nil
+}
+
+func (a *Attribute) normalize(v any) (any, error) {
+	switch a.Type {
+	case AttributeNumber:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+
+		return nil, &SpecError{Attribute: a.Key, Message: "must be a number"}
+	case AttributeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+		return nil, &SpecError{Attribute: a.Key, Message: "must be a boolean"}
+	case AttributeEnum:
+		if s, ok := v.(string); ok && slices.Contains(a.Options, s) {
+			return s, nil
+		}
+
+		return nil, &SpecError{Attribute: a.Key, Message: fmt.Sprintf("must be one of %v", a.Options)}
+	default:
+		if s, ok := v.(string); ok && s != "" {
+			return s, nil
+		}
+
+		return nil, &SpecError{Attribute: a.Key, Message: "must be a non empty string"}
+	}
+}
+
+// ParseFilterValue parses a spec query param value to the text stored for
+// the attribute, so it can be compared with the ->> operator
+func (a *Attribute) ParseFilterValue(v string) (string, error) {
+	switch a.Type {
+	case AttributeNumber:
+		n, err := strconv.ParseFloat(v, 64)
+
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case AttributeBoolean:
+		b, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatBool(b), nil
+	case AttributeEnum:
+		if !slices.Contains(a.Options, v) {
+			return "", fmt.Errorf("must be one of %v", a.Options)
+		}
+
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// SpecFilter filters gear on one attribute, Values are OR-ed and Min/Max
+// only apply to number attributes
+type SpecFilter struct {
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+type SpecFacet struct {
+	Key    string        `json:"key"`
+	Name   string        `json:"name"`
+	Type   AttributeType `json:"type"`
+	Unit   *string       `json:"unit,omitempty"`
+	Values []*FacetValue `json:"values"`
+}
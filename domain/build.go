@@ -0,0 +1,46 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Build is a PC a user plans over time, each slot holds one gear of the
+// category the slot is named after
+type Build struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Name       string    `json:"name" db:"name"`
+	ShareToken *string   `json:"share_token,omitempty" db:"share_token"`
+
+	Slots []*BuildSlot `json:"slots" db:"-"`
+	// Total is the effective price of every slot times its quantity
+	Total float64 `json:"total" db:"-"`
+}
+
+type BuildSlot struct {
+	// Slot is the key of the category of the gear, e.g. "cpu"
+	Slot     string `json:"slot"`
+	Gear     *Gear  `json:"gear"`
+	Quantity int64  `json:"quantity"`
+}
+
+// ComputeTotal sets the total price of the build
+func (b *Build) ComputeTotal() {
+	b.Total = 0
+
+	for _, s := range b.Slots {
+		b.Total += s.Gear.EffectivePrice() * float64(s.Quantity)
+	}
+}
+
+type AddBuildForm struct {
+	Name string `json:"name" conform:"trim" validate:"required,lte=64"`
+}
+
+type UpdateBuildForm struct {
+	Name *string `json:"name,omitempty" db:"name" conform:"trim" validate:"omitempty,lte=64"`
+}
+
+type SetBuildSlotForm struct {
+	Slot     string `json:"slot"     conform:"trim,lower" validate:"required"`
+	GearID   string `json:"gear_id"  conform:"trim"       validate:"required,uuid"`
+	Quantity int64  `json:"quantity"                      validate:"omitempty,gte=1,lte=16"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkPriceRule changes the price and discount of many gear at once, the
+// price change is applied before the rounding
+type BulkPriceRule struct {
+	// Discount sets the discount percentage
+	Discount *float64 `json:"discount,omitempty"     validate:"omitempty,gte=0,lte=100"`
+	// PriceChange raises the price by a percentage, lowers it when negative
+	PriceChange *float64 `json:"price_change,omitempty" validate:"omitempty,gt=-100,lte=1000"`
+	// Round99 rounds the price to the nearest .99, e.g. 10.40 to 9.99
+	Round99 bool `json:"round_99,omitempty"`
+}
+
+// Empty reports whether the rule changes nothing
+func (r *BulkPriceRule) Empty() bool {
+	return r.Discount == nil && r.PriceChange == nil && !r.Round99
+}
+
+// Apply returns the price and discount once the rule is applied, a free
+// price is left alone by the rounding
+func (r *BulkPriceRule) Apply(price float64, discount float64) (float64, float64) {
+	if r.PriceChange != nil {
+		price = math.Round(price*(1+*r.PriceChange/100)*100) / 100
+	}
+
+	if r.Round99 && price > 0 {
+		price = math.Max(math.Round(price+0.01)-0.01, 0.99)
+		price = math.Round(price*100) / 100
+	}
+
+	if r.Discount != nil {
+		discount = *r.Discount
+	}
+
+	return price, discount
+}
+
+// BulkPriceChange is the change of one gear, or one of its variants when
+// VariantID is set, by a bulk operation
+type BulkPriceChange struct {
+	GearID      uuid.UUID  `json:"gear_id" db:"gear_id"`
+	VariantID   *uuid.UUID `json:"variant_id" db:"variant_id"`
+	SKU         *string    `json:"sku" db:"sku"`
+	Name        string     `json:"name" db:"name"`
+	OldPrice    float64    `json:"old_price" db:"old_price"`
+	OldDiscount float64    `json:"old_discount" db:"old_discount"`
+	Price       float64    `json:"price" db:"price"`
+	Discount    float64    `json:"discount" db:"discount"`
+}
+
+// BulkPriceOperation is an executed bulk operation, the changes keep the
+// previous values so it can be undone
+type BulkPriceOperation struct {
+	ID        uuid.UUID          `json:"id" db:"id"`
+	Rule      *BulkPriceRule     `json:"rule" db:"rule"`
+	ActorID   *uuid.UUID         `json:"actor_id" db:"actor_id"`
+	Count     int64              `json:"count" db:"count"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+	UndoneAt  *time.Time         `json:"undone_at" db:"undone_at"`
+	Changes   []*BulkPriceChange `json:"changes,omitempty" db:"-"`
+}
+
+// BulkPriceUndoReport lists the changes that weren't reverted because the
+// gear has changed again since the operation
+type BulkPriceUndoReport struct {
+	Reverted int                `json:"reverted"`
+	Skipped  []*BulkPriceChange `json:"skipped"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+// GearImportRow is one row of a catalog import, keyed by its SKU
+type GearImportRow struct {
+	// Line is the line of the row in the imported file
+	Line int
+	Form *AddGearForm
+	// Err is set when the row couldn't be decoded or validated
+	Err error
+}
+
+type GearImportError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+// GearImportReport is the outcome of a catalog import. An import is all or
+// nothing: when Errors isn't empty nothing was written and Created and
+// Updated count what would have been.
+type GearImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Errors  []*GearImportError `json:"errors"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "github.com/google/uuid"
+
+// AllCategory is the category key matching every gear, it isn't stored
+const AllCategory = "all"
+
+// Category groups gear. Key is used in the API (e.g. "gpu"), Code is the
+// value stored in gear.type (e.g. "GRAPHICS_PROCESSING_UNIT").
+type Category struct {
+	ID       uuid.UUID  `json:"id" db:"id"`
+	Key      string     `json:"key" db:"key"`
+	Code     string     `json:"code" db:"code"`
+	Name     string     `json:"name" db:"name"`
+	ParentID *uuid.UUID `json:"parent_id" db:"parent_id"`
+	Position int64      `json:"position" db:"position"`
+
+	Children []*Category `json:"children,omitempty" db:"-"`
+}
+
+type AddCategoryForm struct {
+	Key       string  `json:"key"        conform:"trim,lower" validate:"required,gte=2,lte=32,excludes= ,ne=all"`
+	Name      string  `json:"name"       conform:"trim"       validate:"required,lte=64"`
+	ParentKey *string `json:"parent_key" conform:"trim,lower" validate:"omitempty"`
+	Position  int64   `json:"position"`
+}
+
+type UpdateCategoryForm struct {
+	Name     *string `json:"name,omitempty"       db:"name"     conform:"trim"       validate:"omitempty,lte=64"`
+	Position *int64  `json:"position,omitempty"   db:"position"                      validate:"omitempty"`
+	// ParentKey moves the category, an empty string makes it a root category
+	ParentKey *string `json:"parent_key,omitempty" db:"-"        conform:"trim,lower" validate:"omitempty"`
+}
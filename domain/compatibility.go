@@ -0,0 +1,42 @@
+package domain
+
+type CompatibilityStatus string
+
+// the statuses are ordered from best to worst
+const (
+	CompatibilityPass CompatibilityStatus = "pass"
+	CompatibilityWarn CompatibilityStatus = "warn"
+	CompatibilityFail CompatibilityStatus = "fail"
+)
+
+var compatibilitySeverity = map[CompatibilityStatus]int{
+	CompatibilityPass: 0,
+	CompatibilityWarn: 1,
+	CompatibilityFail: 2,
+}
+
+// Worse returns the worse of s and o
+func (s CompatibilityStatus) Worse(o CompatibilityStatus) CompatibilityStatus {
+	if compatibilitySeverity[o] > compatibilitySeverity[s] {
+		return o
+	}
+
+	return s
+}
+
+type CompatibilityResult struct {
+	Rule    string              `json:"rule"`
+	Status  CompatibilityStatus `json:"status"`
+	Message string              `json:"message"`
+}
+
+type CompatibilityReport struct {
+	// Status is the worst status of the results, pass when no rule applies
+	Status  CompatibilityStatus    `json:"status"`
+	Results []*CompatibilityResult `json:"results"`
+}
+
+type CheckCompatibilityForm struct {
+	// GearIDs may repeat an id, e.g. two sticks of the same RAM
+	GearIDs []string `json:"gear_ids" validate:"required,min=1,max=32,dive,uuid"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound          = errors.New("resource not found")
+	ErrForbidden         = errors.New("you do not have permission to access this resource")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidImageOrder = errors.New("image_ids must list every image of the gear exactly once")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrUnsupportedImage  = errors.New("image must be a JPEG, PNG or WebP")
+	ErrImageDimensions   = errors.New("image dimensions are too large")
+	ErrInvalidVariant    = errors.New("invalid variant")
+	ErrVariantRequired   = errors.New("gear has variants, variant_id is required")
+	ErrVariantInUse      = errors.New("variant has been ordered, it can't be deleted")
+	ErrSKUTaken          = errors.New("sku has already been used")
+	ErrGearArchived      = errors.New("gear is archived")
+	ErrGearNotArchived   = errors.New("gear must be archived before it is purged")
+	ErrGearInUse         = errors.New("gear has been ordered, it can't be purged")
+	ErrInvalidCatalog    = errors.New("invalid catalog")
+	ErrEmptyBulkRule     = errors.New("rule must change the price or the discount")
+	ErrBulkUndone        = errors.New("bulk operation has already been undone")
+	ErrInvalidPromotion  = errors.New("invalid promotion")
+	ErrOrderNotCart      = errors.New("order is not a cart")
+	ErrCartEmpty         = errors.New("cart is empty")
+)
+
+// FilterError reports a query param of a list filter that can't be used
+type FilterError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("query param '%v' %v", e.Param, e.Message)
+}
+
+// SpecError reports a gear specification that doesn't match the attributes
+// of the gear's category
+type SpecError struct {
+	Attribute string `json:"attribute"`
+	Message   string `json:"message"`
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("spec '%v' %v", e.Attribute, e.Message)
+}
@@ -1,23 +1,14 @@
 package domain
 
-import "github.com/google/uuid"
-
-var GearTypeMap map[string]string = map[string]string{
-	"all":       "ALL",
-	"pc":        "PERSONAL_COMPUTER",
-	"laptop":    "LAPTOP",
-	"mainboard": "MAINBOARD",
-	"cpu":       "CENTRAL_PROCESSING_UNIT",
-	"gpu":       "GRAPHICS_PROCESSING_UNIT",
-	"psu":       "POWER_SUPPLY_UNIT",
-	"ram":       "RANDOM_ACCESS_MEMORY",
-	"fan":       "FAN",
-	"storage":   "STORAGE",
-	"monitor":   "MONITOR",
-}
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Gear struct {
 	ID       uuid.UUID `json:"id" db:"id"`
+	SKU      string    `json:"sku" db:"sku"`
 	Name     string    `json:"name" db:"name"`
 	Type     string    `json:"type" db:"type"`
 	Brand    string    `json:"brand" db:"brand"`
@@ -26,19 +17,151 @@ type Gear struct {
 	Discount float64   `json:"discount" db:"discount"`
 	Quantity int64     `json:"quantity" db:"quantity"`
 	ImageURL string    `json:"image_url" db:"image_url"`
+	Specs    Specs     `json:"specs" db:"specs"`
+
+	// LowestPrice is the lowest discounted price of the last
+	// LowestPriceDays days, current price included
+	LowestPrice *float64 `json:"lowest_price,omitempty" db:"lowest_price"`
+
+	// Promotion is the promotion running for the gear, its discount applies
+	// instead of Discount when it is bigger
+	Promotion *AppliedPromotion `json:"promotion,omitempty" db:"promotion"`
+
+	// ImageRenditions are the renditions of the primary image, listing
+	// pages should pick one of these rather than ImageURL
+	ImageRenditions []*ImageRendition `json:"image_renditions" db:"image_renditions"`
+
+	// Highlight is only set when listing with a search query, it holds the
+	// HTML-escaped text with the matches wrapped in <mark> tags
+	Highlight *string `json:"highlight,omitempty" db:"highlight"`
+
+	// VariantAxes are the options the gear is sold in, a gear with axes is
+	// bought through its variants. Its price, discount and stock mirror them:
+	// the cheapest variant and the total stock.
+	VariantAxes []*VariantAxis `json:"variant_axes" db:"variant_axes"`
+
+	// Images is the gallery in display order
+	Images []*GearImage `json:"images,omitempty" db:"-"`
+
+	// Variants are only loaded with a single gear
+	Variants []*GearVariant `json:"variants,omitempty" db:"-"`
+
+	// ArchivedAt is set once the gear is archived, it is then hidden from
+	// the lists and can't be added to a cart but past orders still show it
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+}
+
+// Archived reports whether the gear has been archived
+func (g *Gear) Archived() bool {
+	return g.ArchivedAt != nil
+}
+
+// HasVariants reports whether the gear is bought through its variants
+func (g *Gear) HasVariants() bool {
+	return len(g.VariantAxes) > 0
+}
+
+// withPromotion returns the discount of the running promotion when it is
+// bigger than discount
+func (g *Gear) withPromotion(discount float64) float64 {
+	if g.Promotion != nil && g.Promotion.Discount > discount {
+		return g.Promotion.Discount
+	}
+
+	return discount
+}
+
+// EffectiveDiscount is the discount percentage the gear is sold at
+func (g *Gear) EffectiveDiscount() float64 {
+	return g.withPromotion(g.Discount)
+}
+
+// EffectivePrice is the price after the effective discount percentage
+func (g *Gear) EffectivePrice() float64 {
+	return g.Price * (1 - g.EffectiveDiscount()/100)
+}
+
+// VariantPrice is the effective price of a variant of the gear, the
+// promotions of the gear apply to its variants
+func (g *Gear) VariantPrice(v *GearVariant) float64 {
+	return v.Price * (1 - g.withPromotion(v.Discount)/100)
 }
 
 type ListGearFilter struct {
-	Page     *int64  `query:"page"`
-	Limit    *int64  `query:"limit"`
+	Page        *int64   `query:"page"`
+	Limit       *int64   `query:"limit"`
+	Cursor      *string  `query:"cursor"`
+	WithCount   *bool    `query:"with_count"`
+	Category    *string  `query:"category"`
+	Query       *string  `query:"q"`
+	Brand       []string `query:"brand"`
+	Variety     []string `query:"variety"`
+	MinPrice    *float64 `query:"min_price"`
+	MaxPrice    *float64 `query:"max_price"`
+	MinDiscount *float64 `query:"min_discount"`
+	MaxDiscount *float64 `query:"max_discount"`
+	InStock     *bool    `query:"in_stock"`
+	// Price is the legacy "start,end" exclusive range, -1 leaves a side open.
+	// Prefer MinPrice and MaxPrice.
+	Price *string `query:"price"`
+	Sort  *string `query:"sort"`
+	// Specs are the "spec.<key>", "spec.<key>.min" and "spec.<key>.max"
+	// params keyed by attribute key
+	Specs map[string]*SpecFilter `query:"-"`
+	// Archived lists the archived gear instead of the active one, it is
+	// only set by the admin routes
+	Archived bool `query:"-"`
+}
+
+// DefaultPriceBuckets are the lower bounds of the price facet buckets used
+// when the request doesn't give its own
+var DefaultPriceBuckets []float64 = []float64{0, 100, 250, 500, 1000, 2000}
+
+type FacetValue struct {
+	Value string `json:"value" db:"value"`
+	Count int64  `json:"count" db:"count"`
+}
+
+type PriceBucket struct {
+	Min   float64  `json:"min" db:"min"`
+	Max   *float64 `json:"max" db:"max"`
+	Count int64    `json:"count" db:"count"`
+}
+
+type GearFacets struct {
+	Total   int64          `json:"total"`
+	Brand   []*FacetValue  `json:"brand"`
+	Variety []*FacetValue  `json:"variety"`
+	Price   []*PriceBucket `json:"price"`
+	// Specs is only filled when the filter picks a category
+	Specs []*SpecFacet `json:"specs"`
+}
+
+type SuggestionKind string
+
+const (
+	SuggestionName     SuggestionKind = "name"
+	SuggestionBrand    SuggestionKind = "brand"
+	SuggestionCategory SuggestionKind = "category"
+)
+
+type GearSuggestion struct {
+	Kind     SuggestionKind `json:"kind" db:"kind"`
+	Value    string         `json:"value" db:"value"`
+	Category string         `json:"category" db:"category"`
+	GearID   *uuid.UUID     `json:"gear_id,omitempty" db:"gear_id"`
+	Score    float64        `json:"score" db:"score"`
+}
+
+type SuggestGearFilter struct {
+	Query    string  `query:"q"`
 	Category *string `query:"category"`
-	Brand    *string `query:"brand"`
-	Variety  *string `query:"variety"`
-	Price    *string `query:"price"`
-	Sort     *string `query:"sort"`
+	// Limit caps the number of suggestions of each kind per category
+	Limit *int64 `query:"limit"`
 }
 
 type AddGearForm struct {
+	SKU         *string `json:"sku,omitempty"           conform:"trim" validate:"omitempty,lte=64"`
 	Name        string  `json:"name,omitempty"          conform:"trim" validate:"required"`
 	Type        string  `json:"type,omitempty"          conform:"trim" validate:"required,is-gear"`
 	Brand       string  `json:"brand"                   conform:"trim" validate:"required"`
@@ -47,9 +170,11 @@ type AddGearForm struct {
 	Discount    float64 `json:"discount,omitempty"      conform:"trim" `
 	Quantity    int64   `json:"quantity,omitempty"      conform:"trim" `
 	ImageBase64 *string `json:"image_base64,omitempty"  conform:"trim" `
+	Specs       Specs   `json:"specs,omitempty"`
 }
 
 type UpdateGearForm struct {
+	SKU         *string  `json:"sku,omitempty"          db:"sku"        conform:"trim"  validate:"omitempty,lte=64"`
 	Name        *string  `json:"name,omitempty"         db:"name"       conform:"trim"  validate:"omitempty"`
 	Type        *string  `json:"type,omitempty"         db:"type"       conform:"trim"  validate:"omitempty,is-gear"`
 	Brand       *string  `json:"brand"                  db:"brand"      conform:"trim"  validate:"omitempty"`
@@ -57,5 +182,6 @@ type UpdateGearForm struct {
 	Price       *float64 `json:"price,omitempty"        db:"price"      conform:"trim"  validate:"omitempty"`
 	Discount    *float64 `json:"discount,omitempty"     db:"discount"                   validate:"omitempty"`
 	Quantity    *int64   `json:"quantity,omitempty"     db:"quantity"                   validate:"omitempty"`
-	ImageBase64 *string  `json:"image_base64,omitempty" db:"image_base64"               validate:"omitempty"`
+	ImageBase64 *string  `json:"image_base64,omitempty" db:"-"                          validate:"omitempty"`
+	Specs       *Specs   `json:"specs,omitempty"        db:"specs"                      validate:"omitempty"`
 }
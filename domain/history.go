@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LowestPriceDays is the window of Gear.LowestPrice
+const LowestPriceDays = 30
+
+// GearChange is who changes the price, discount or stock of gear and why,
+// it is recorded with every change made in a transaction started with a
+// context carrying it (see WithGearChange)
+type GearChange struct {
+	ActorID *uuid.UUID
+	Reason  string
+}
+
+type gearChangeKey struct{}
+
+func WithGearChange(ctx context.Context, change *GearChange) context.Context {
+	return context.WithValue(ctx, gearChangeKey{}, change)
+}
+
+// GearChangeFrom returns the change stored by WithGearChange, or nil
+func GearChangeFrom(ctx context.Context) *GearChange {
+	change, _ := ctx.Value(gearChangeKey{}).(*GearChange)
+	return change
+}
+
+// GearHistory is one recorded change of the price, discount or stock of a
+// gear, or of one of its variants when VariantID is set, which it stays once
+// the variant is deleted. The old values are nil for the first record.
+type GearHistory struct {
+	ID          int64      `json:"id" db:"id"`
+	GearID      uuid.UUID  `json:"gear_id" db:"gear_id"`
+	VariantID   *uuid.UUID `json:"variant_id" db:"variant_id"`
+	Price       float64    `json:"price" db:"price"`
+	Discount    float64    `json:"discount" db:"discount"`
+	Quantity    int64      `json:"quantity" db:"quantity"`
+	OldPrice    *float64   `json:"old_price" db:"old_price"`
+	OldDiscount *float64   `json:"old_discount" db:"old_discount"`
+	OldQuantity *int64     `json:"old_quantity" db:"old_quantity"`
+	ActorID     *uuid.UUID `json:"actor_id" db:"actor_id"`
+	Reason      string     `json:"reason" db:"reason"`
+	ChangedAt   time.Time  `json:"changed_at" db:"changed_at"`
+}
+
+type ListGearHistoryFilter struct {
+	GearID string
+	// VariantID only keeps the changes of one variant
+	VariantID *string
+	Cursor    *string
+	Page      int64
+	Limit     int64
+}
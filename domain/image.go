@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"image"
+
+	"github.com/google/uuid"
+)
+
+// GearImage is one picture of the gallery of a gear. The URL of the primary
+// image is also kept in Gear.ImageURL for the clients that only show one.
+type GearImage struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	GearID   uuid.UUID `json:"gear_id" db:"gear_id"`
+	URL      string    `json:"url" db:"url"`
+	AltText  string    `json:"alt_text" db:"alt_text"`
+	Position int64     `json:"position" db:"position"`
+	Primary  bool      `json:"primary" db:"is_primary"`
+
+	Renditions []*ImageRendition `json:"renditions" db:"renditions"`
+}
+
+// URLs lists every stored file of the image, renditions included
+func (i *GearImage) URLs() []string {
+	result := []string{i.URL}
+
+	for _, r := range i.Renditions {
+		result = append(result, r.JPEG, r.WebP)
+	}
+
+	return result
+}
+
+type AddGearImageForm struct {
+	ImageBase64 string `json:"image_base64"                validate:"required,base64"`
+	AltText     string `json:"alt_text"     conform:"trim" validate:"lte=256"`
+	// Primary makes the image the primary one, the first image of a gear
+	// always is
+	Primary bool `json:"primary"`
+}
+
+// NewGearImage is a decoded image to add to a gallery, both the base64 and
+// the multipart uploads end up as one
+type NewGearImage struct {
+	Image   image.Image
+	AltText string
+	Primary bool
+}
+
+type UpdateGearImageForm struct {
+	AltText *string `json:"alt_text,omitempty" db:"alt_text" conform:"trim" validate:"omitempty,lte=256"`
+}
+
+type ReorderGearImageForm struct {
+	// ImageIDs lists every image of the gear in the new order
+	ImageIDs []string `json:"image_ids" validate:"required,dive,uuid"`
+}
+
+// ImageRendition is one resized copy of an image, stored as both JPEG and
+// WebP so clients can build a srcset
+type ImageRendition struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	JPEG   string `json:"jpeg"`
+	WebP   string `json:"webp"`
+}
+
+// ImageReconcileReport lists the stored images no gear references anymore
+type ImageReconcileReport struct {
+	// DryRun reports the orphans without deleting them
+	DryRun bool `json:"dry_run"`
+	// Checked is the number of stored objects compared to the references
+	Checked int      `json:"checked"`
+	Orphans []string `json:"orphans"`
+	// Failed lists the orphans that couldn't be deleted
+	Failed []string `json:"failed"`
+}
@@ -21,8 +21,20 @@ type Order struct {
 }
 
 type OrderGear struct {
-	Gear     *Gear `json:"gear"`
-	Quantity int64 `json:"quantity"`
+	Gear *Gear `json:"gear"`
+	// Variant is set for the lines of a gear with variants, its price and
+	// stock apply instead of the gear's
+	Variant  *GearVariant `json:"variant,omitempty"`
+	Quantity int64        `json:"quantity"`
+}
+
+// UnitPrice is the price of one item of the line, discounts included
+func (og *OrderGear) UnitPrice() float64 {
+	if og.Variant != nil {
+		return og.Gear.VariantPrice(og.Variant)
+	}
+
+	return og.Gear.EffectivePrice()
 }
 
 type FullOrder struct {
@@ -0,0 +1,11 @@
+package domain
+
+// Page is one page of a keyset paginated list. NextCursor and PrevCursor are
+// opaque tokens to send back as the "cursor" query param, they are nil when
+// there is no page in that direction.
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+	Total      *int64  `json:"total,omitempty"`
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromotionStatus string
+
+const (
+	PromotionScheduled PromotionStatus = "scheduled"
+	PromotionRunning   PromotionStatus = "running"
+	PromotionEnded     PromotionStatus = "ended"
+)
+
+// Promotion discounts the gear it targets between StartsAt and EndsAt. Gear
+// is targeted by its id, its brand or its category, subcategories included
+// and AllCategory for every gear. When several promotions run for a gear
+// the highest Priority wins, then the biggest discount, then the oldest.
+type Promotion struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	Name       string      `json:"name" db:"name"`
+	Discount   float64     `json:"discount" db:"discount"`
+	Priority   int64       `json:"priority" db:"priority"`
+	StartsAt   time.Time   `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time   `json:"ends_at" db:"ends_at"`
+	GearIDs    []uuid.UUID `json:"gear_ids" db:"gear_ids"`
+	Brands     []string    `json:"brands" db:"brands"`
+	Categories []string    `json:"categories" db:"categories"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+// AppliedPromotion is the promotion running for a gear, as shown with it
+type AppliedPromotion struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Discount float64   `json:"discount"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type AddPromotionForm struct {
+	Name       string    `json:"name"       conform:"trim" validate:"required,lte=64"`
+	Discount   float64   `json:"discount"                  validate:"gt=0,lte=100"`
+	Priority   int64     `json:"priority"`
+	StartsAt   time.Time `json:"starts_at"                 validate:"required"`
+	EndsAt     time.Time `json:"ends_at"                   validate:"required,gtfield=StartsAt"`
+	GearIDs    []string  `json:"gear_ids"                  validate:"dive,uuid"`
+	Brands     []string  `json:"brands"                    validate:"dive,required"`
+	Categories []string  `json:"categories"                validate:"dive,required"`
+}
+
+type UpdatePromotionForm struct {
+	Name       *string    `json:"name,omitempty"       db:"name"       conform:"trim" validate:"omitempty,lte=64"`
+	Discount   *float64   `json:"discount,omitempty"   db:"discount"                  validate:"omitempty,gt=0,lte=100"`
+	Priority   *int64     `json:"priority,omitempty"   db:"priority"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"  db:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"    db:"ends_at"`
+	GearIDs    *[]string  `json:"gear_ids,omitempty"   db:"gear_ids"                  validate:"omitempty,dive,uuid"`
+	Brands     *[]string  `json:"brands,omitempty"     db:"brands"                    validate:"omitempty,dive,required"`
+	Categories *[]string  `json:"categories,omitempty" db:"categories"                validate:"omitempty,dive,required"`
+}
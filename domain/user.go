@@ -1,6 +1,43 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	CUSTOMER Role = "CUSTOMER"
+	STAFF    Role = "STAFF"
+	ADMIN    Role = "ADMIN"
+)
+
+type Permission string
+
+const (
+	PermissionManageGear  Permission = "gear:manage"
+	PermissionManageOrder Permission = "order:manage"
+	PermissionManageUser  Permission = "user:manage"
+)
+
+var RolePermissionMap map[Role][]Permission = map[Role][]Permission{
+	CUSTOMER: {},
+	STAFF: {
+		PermissionManageGear,
+	},
+	ADMIN: {
+		PermissionManageGear,
+		PermissionManageOrder,
+		PermissionManageUser,
+	},
+}
+
+// Can reports whether the role has been granted the permission p
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(RolePermissionMap[r], p)
+}
 
 type User struct {
 	ID        uuid.UUID `json:"id" db:"id"`
@@ -11,6 +48,10 @@ type User struct {
 	Phone     string    `json:"phone" db:"phone"`
 	Password  string    `json:"password" db:"password"`
 	Verified  bool      `json:"verified" db:"verified"`
+	Role      Role      `json:"role" db:"role"`
+
+	// SessionVersion is raised when the role changes to end the sessions
+	SessionVersion int64 `json:"-" db:"session_version"`
 }
 
 type UserInfo struct {
@@ -20,6 +61,10 @@ type UserInfo struct {
 	FirstName string    `json:"first_name" db:"first_name"`
 	LastName  string    `json:"last_name" db:"last_name"`
 	Phone     string    `json:"phone" db:"phone"`
+	Role      Role      `json:"role" db:"role"`
+
+	// SessionVersion is the User.SessionVersion the session was issued for
+	SessionVersion int64 `json:"-" db:"session_version"`
 }
 
 type UserCredential struct {
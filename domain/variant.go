@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// VariantAxis is an option a gear is sold in, e.g. capacity or color
+type VariantAxis struct {
+	Key  string `json:"key"  conform:"trim,lower" validate:"required,lte=32"`
+	Name string `json:"name" conform:"trim"       validate:"required,lte=64"`
+}
+
+// GearVariant is one purchasable combination of the variant axes of a gear,
+// with its own SKU, price and stock. The JSON names match the columns so an
+// order line can load it with to_jsonb.
+type GearVariant struct {
+	ID       uuid.UUID         `json:"id" db:"id"`
+	GearID   uuid.UUID         `json:"gear_id" db:"gear_id"`
+	SKU      string            `json:"sku" db:"sku"`
+	Options  map[string]string `json:"options" db:"options"`
+	Price    float64           `json:"price" db:"price"`
+	Discount float64           `json:"discount" db:"discount"`
+	Quantity int64             `json:"quantity" db:"quantity"`
+	// ImageID is one of the gallery images of the gear
+	ImageID  *uuid.UUID `json:"image_id" db:"image_id"`
+	Position int64      `json:"position" db:"position"`
+}
+
+// EffectivePrice is the price after the discount percentage
+func (v *GearVariant) EffectivePrice() float64 {
+	return v.Price * (1 - v.Discount/100)
+}
+
+type SetVariantAxesForm struct {
+	Axes []*VariantAxis `json:"axes" validate:"dive"`
+}
+
+type AddGearVariantForm struct {
+	SKU      string            `json:"sku"      conform:"trim" validate:"required,lte=64"`
+	Options  map[string]string `json:"options"                 validate:"required"`
+	Price    float64           `json:"price"                   validate:"gte=0"`
+	Discount float64           `json:"discount"                validate:"gte=0,lte=100"`
+	Quantity int64             `json:"quantity"                validate:"gte=0"`
+	ImageID  *string           `json:"image_id,omitempty"      validate:"omitempty,uuid"`
+}
+
+type UpdateGearVariantForm struct {
+	SKU      *string            `json:"sku,omitempty"      db:"sku"      conform:"trim" validate:"omitempty,lte=64"`
+	Options  *map[string]string `json:"options,omitempty"  db:"options"                 validate:"omitempty"`
+	Price    *float64           `json:"price,omitempty"    db:"price"                   validate:"omitempty,gte=0"`
+	Discount *float64           `json:"discount,omitempty" db:"discount"                validate:"omitempty,gte=0,lte=100"`
+	Quantity *int64             `json:"quantity,omitempty" db:"quantity"                validate:"omitempty,gte=0"`
+	ImageID  *string            `json:"image_id,omitempty" db:"image_id"                validate:"omitempty,uuid"`
+}
+
+// ValidateVariantOptions checks options gives a value to every axis and
+// nothing else
+func ValidateVariantOptions(axes []*VariantAxis, options map[string]string) error {
+	keys := []string{}
+
+	for _, a := range axes {
+		keys = append(keys, a.Key)
+
+		if options[a.Key] == "" {
+			return fmt.Errorf("%w: option '%v' is required", ErrInvalidVariant, a.Key)
+		}
+	}
+
+	for key := range options {
+		if !slices.Contains(keys, key) {
+			return fmt.Errorf("%w: option '%v' is not a variant axis of the gear", ErrInvalidVariant, key)
+		}
+	}
+
+	return nil
+}
+
+// SameOptions reports whether two variants are the same combination
+func SameOptions(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,37 @@
+// Package catalog reads and writes the gear catalog as CSV or JSON Lines,
+// one domain.AddGearForm per row keyed by its SKU
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// Columns are the CSV columns, specs is a JSON object
+var Columns = []string{"sku", "name", "type", "brand", "variety", "price", "discount", "quantity", "image_base64", "specs"}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSONL:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: format must be csv or jsonl", domain.ErrInvalidCatalog)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv"
+	}
+
+	return "application/x-ndjson"
+}
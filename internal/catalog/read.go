@@ -0,0 +1,183 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/leebenson/conform"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// ReadRows decodes every row of r and validates it with v the way the
+// create endpoint does. A row that can't be used gets its Err set, the
+// returned error is only for a file that can't be read at all.
+func ReadRows(ctx context.Context, r io.Reader, format Format, v *validator.Validate) ([]*domain.GearImportRow, error) {
+	var rows []*domain.GearImportRow
+	var err error
+
+	if format == FormatCSV {
+		rows, err = readCSV(r)
+	} else {
+		rows, err = readJSONL(r)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if row.Err == nil {
+			row.Err = validateRow(ctx, row.Form, v)
+		}
+	}
+
+	return rows, nil
+}
+
+func validateRow(ctx context.Context, form *domain.AddGearForm, v *validator.Validate) error {
+	err := conform.Strings(form)
+
+	if err != nil {
+		return err
+	}
+
+	if form.SKU == nil || *form.SKU == "" {
+		return errors.New("sku is required")
+	}
+
+	return v.StructCtx(ctx, form)
+}
+
+func readCSV(r io.Reader) ([]*domain.GearImportRow, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: csv header is required", domain.ErrInvalidCatalog)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCatalog, err)
+	}
+
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+
+		if !slices.Contains(Columns, header[i]) {
+			return nil, fmt.Errorf("%w: unknown column %v", domain.ErrInvalidCatalog, name)
+		}
+	}
+
+	if !slices.Contains(header, "sku") {
+		return nil, fmt.Errorf("%w: column sku is required", domain.ErrInvalidCatalog)
+	}
+
+	rows := []*domain.GearImportRow{}
+
+	for {
+		record, err := cr.Read()
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := &domain.GearImportRow{Line: line}
+
+		// a row with a wrong number of fields is still returned, any other
+		// parse error leaves the reader lost
+		if errors.Is(err, csv.ErrFieldCount) {
+			row.Err = fmt.Errorf("row has %v fields, the header has %v", len(record), len(header))
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCatalog, err)
+		} else {
+			row.Form, row.Err = decodeRecord(header, record)
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func decodeRecord(header []string, record []string) (*domain.AddGearForm, error) {
+	form := &domain.AddGearForm{}
+
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+
+		var err error
+
+		switch header[i] {
+		case "sku":
+			form.SKU = &value
+		case "name":
+			form.Name = value
+		case "type":
+			form.Type = value
+		case "brand":
+			form.Brand = value
+		case "variety":
+			form.Variety = value
+		case "price":
+			form.Price, err = strconv.ParseFloat(value, 64)
+		case "discount":
+			form.Discount, err = strconv.ParseFloat(value, 64)
+		case "quantity":
+			form.Quantity, err = strconv.ParseInt(value, 10, 64)
+		case "image_base64":
+			form.ImageBase64 = &value
+		case "specs":
+			err = json.Unmarshal([]byte(value), &form.Specs)
+		}
+
+		if err != nil {
+			return form, fmt.Errorf("column %v: %w", header[i], err)
+		}
+	}
+
+	return form, nil
+}
+
+func readJSONL(r io.Reader) ([]*domain.GearImportRow, error) {
+	br := bufio.NewReader(r)
+	rows := []*domain.GearImportRow{}
+
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCatalog, err)
+		}
+
+		if len(bytes.TrimSpace(b)) > 0 {
+			row := &domain.GearImportRow{
+				Line: line,
+				Form: &domain.AddGearForm{},
+			}
+
+			// a misspelled field would silently be left out otherwise
+			d := json.NewDecoder(bytes.NewReader(b))
+			d.DisallowUnknownFields()
+
+			row.Err = d.Decode(row.Form)
+			rows = append(rows, row)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+	}
+}
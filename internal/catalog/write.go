@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// Writer writes gear in the format ReadRows reads
+type Writer struct {
+	format Format
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+func NewWriter(w io.Writer, format Format) *Writer {
+	if format == FormatCSV {
+		return &Writer{format: format, csv: csv.NewWriter(w)}
+	}
+
+	return &Writer{format: format, json: json.NewEncoder(w)}
+}
+
+func (w *Writer) writeHeader() error {
+	if w.header || w.format != FormatCSV {
+		return nil
+	}
+
+	w.header = true
+
+	return w.csv.Write(Columns)
+}
+
+func (w *Writer) Write(form *domain.AddGearForm) error {
+	if w.format != FormatCSV {
+		return w.json.Encode(form)
+	}
+
+	err := w.writeHeader()
+
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(Columns))
+
+	for i, column := range Columns {
+		switch column {
+		case "sku":
+			if form.SKU != nil {
+				record[i] = *form.SKU
+			}
+		case "name":
+			record[i] = form.Name
+		case "type":
+			record[i] = form.Type
+		case "brand":
+			record[i] = form.Brand
+		case "variety":
+			record[i] = form.Variety
+		case "price":
+			record[i] = strconv.FormatFloat(form.Price, 'f', -1, 64)
+		case "discount":
+			record[i] = strconv.FormatFloat(form.Discount, 'f', -1, 64)
+		case "quantity":
+			record[i] = strconv.FormatInt(form.Quantity, 10)
+		case "image_base64":
+			if form.ImageBase64 != nil {
+				record[i] = *form.ImageBase64
+			}
+		case "specs":
+			if len(form.Specs) > 0 {
+				b, err := json.Marshal(form.Specs)
+
+				if err != nil {
+					return err
+				}
+
+				record[i] = string(b)
+			}
+		}
+	}
+
+	return w.csv.Write(record)
+}
+
+// Flush writes the buffered rows, a CSV file gets its header even without
+// rows
+func (w *Writer) Flush() error {
+	if w.format != FormatCSV {
+		return nil
+	}
+
+	err := w.writeHeader()
+
+	if err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
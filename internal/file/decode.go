@@ -0,0 +1,110 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	b64 "encoding/base64"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// MaxImageBytes is the largest encoded image accepted
+const MaxImageBytes = 10 << 20
+
+// MaxImagePixels bounds the decoded size of an image, a small file can
+// declare huge dimensions and exhaust the memory once decoded
+const MaxImagePixels = 40_000_000
+
+// imageMagic are the leading bytes of the accepted formats, "?" matches any
+// byte
+var imageMagic = []string{
+	"\xff\xd8\xff",      // jpeg
+	"\x89PNG\r\n\x1a\n", // png
+	"RIFF????WEBP",      // webp
+}
+
+func hasImageMagic(data []byte) bool {
+	for _, magic := range imageMagic {
+		if len(data) < len(magic) {
+			continue
+		}
+
+		ok := true
+
+		for i := 0; i < len(magic); i++ {
+			if magic[i] != '?' && magic[i] != data[i] {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DecodeImage decodes the image as it streams from r, reading at most
+// MaxImageBytes. Only the header is kept aside: the format is checked by its
+// magic bytes and the dimensions by the header before the pixels are decoded.
+func DecodeImage(r io.Reader) (image.Image, error) {
+	lr := &io.LimitedReader{R: r, N: MaxImageBytes + 1}
+	br := bufio.NewReader(lr)
+
+	// "RIFF????WEBP" is the longest magic
+	magic, _ := br.Peek(12)
+
+	if !hasImageMagic(magic) {
+		return nil, domain.ErrUnsupportedImage
+	}
+
+	// the header read by DecodeConfig is replayed to Decode
+	var header bytes.Buffer
+
+	config, _, err := image.DecodeConfig(io.TeeReader(br, &header))
+	if err != nil {
+		return nil, decodeError(lr, domain.ErrUnsupportedImage)
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxImagePixels {
+		return nil, domain.ErrImageDimensions
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&header, br))
+	if err != nil {
+		return nil, decodeError(lr, domain.ErrUnsupportedImage)
+	}
+
+	// the decoder may stop before the end, what is left still counts
+	// toward the limit
+	_, err = io.Copy(io.Discard, br)
+	if err != nil {
+		return nil, err
+	}
+
+	if lr.N <= 0 {
+		return nil, domain.ErrImageTooLarge
+	}
+
+	return img, nil
+}
+
+// decodeError reports an image cut by the MaxImageBytes limit as too large
+// rather than as err
+func decodeError(lr *io.LimitedReader, err error) error {
+	if lr.N <= 0 {
+		return domain.ErrImageTooLarge
+	}
+
+	return err
+}
+
+// DecodeBase64Image decodes an image sent as a base64 string
+func DecodeBase64Image(base64 string) (image.Image, error) {
+	return DecodeImage(b64.NewDecoder(b64.StdEncoding, strings.NewReader(base64)))
+}
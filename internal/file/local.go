@@ -0,0 +1,185 @@
+package image
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage keeps the objects as files under Dir and serves them itself
+// (see ServeHTTP) from BaseURL. It is meant for development, every object is
+// public and a signed URL only adds an expiry checked when it is served.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+	// Secret signs the signed URLs, they stop working when it changes
+	Secret []byte
+}
+
+func NewLocalStorage(dir string, baseURL string, secret []byte) (*LocalStorage, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/"), Secret: secret}, nil
+}
+
+// path maps key to a file under Dir, keys escaping Dir are rejected
+func (s *LocalStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so a failed upload never leaves a
+// partial object behind
+func (s *LocalStorage) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, body)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	result := []Object{}
+
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		result = append(result, Object{Key: key, ModTime: info.ModTime()})
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *LocalStorage) PublicURL(key string) string {
+	return fmt.Sprintf("%v/%v", s.BaseURL, key)
+}
+
+func (s *LocalStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "%v\n%v", key, expires)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	expiresAt := time.Now().Add(expires).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", s.sign(key, expiresAt))
+
+	return fmt.Sprintf("%v?%v", s.PublicURL(key), query.Encode()), nil
+}
+
+// ServeHTTP serves the object at the request path, which must have the prefix
+// of BaseURL stripped. Requests made with a signed URL are rejected once it
+// expired or when the signature doesn't match.
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	key := strings.TrimPrefix(req.URL.Path, "/")
+
+	path, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	query := req.URL.Query()
+
+	if query.Has("signature") {
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+
+		valid := err == nil &&
+			time.Now().Unix() <= expires &&
+			hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(key, expires)))
+
+		if !valid {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+	}
+
+	http.ServeFile(w, req, path)
+}
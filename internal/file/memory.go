@@ -0,0 +1,107 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryObject is an object kept by MemoryStorage
+type MemoryObject struct {
+	ContentType string
+	Data        []byte
+	ModTime     time.Time
+}
+
+// MemoryStorage keeps the objects in memory, they are lost when the process
+// exits. It is meant for tests and running the service without any storage.
+type MemoryStorage struct {
+	BaseURL string
+
+	mu      sync.RWMutex
+	objects map[string]*MemoryObject
+}
+
+func NewMemoryStorage(baseURL string) *MemoryStorage {
+	return &MemoryStorage{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		objects: map[string]*MemoryObject{},
+	}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = &MemoryObject{
+		ContentType: contentType,
+		Data:        data,
+		ModTime:     time.Now(),
+	}
+
+	return nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+
+	return nil
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []Object{}
+
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, Object{Key: key, ModTime: object.ModTime})
+		}
+	}
+
+	return result, nil
+}
+
+// Get returns the object at key, or nil when there is none
+func (s *MemoryStorage) Get(key string) *MemoryObject {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.objects[key]
+}
+
+func (s *MemoryStorage) PublicURL(key string) string {
+	return fmt.Sprintf("%v/%v", s.BaseURL, key)
+}
+
+// SignedURL of the memory storage is not signed, every object is public
+func (s *MemoryStorage) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return fmt.Sprintf("%v?expires=%v", s.PublicURL(key), time.Now().Add(expires).Unix()), nil
+}
+
+// ServeHTTP serves the object at the request path, which must have the prefix
+// of BaseURL stripped
+func (s *MemoryStorage) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	object := s.Get(strings.TrimPrefix(req.URL.Path, "/"))
+
+	if object == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Write(object.Data)
+}
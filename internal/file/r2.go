@@ -1,61 +1,95 @@
 package image
 
 import (
-	"bytes"
 	"context"
-	b64 "encoding/base64"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
-	"image"
-	"image/jpeg"
-	_ "image/png"
-
-	_ "golang.org/x/image/webp"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/disintegration/imaging"
 )
 
-const BUCKET_NAME = "gear-ecommerce"
-const IMAGE_LOCATION = "image-gear-ecommerce.goldenfealla.dev"
+// putTimeout bounds a single upload, a stuck upload would otherwise hold the
+// request until the client gives up
+const putTimeout = time.Second * 5
 
-func UploadImageJpeg(client *s3.Client, base64 string, fileName string) (*string, error) {
-	// convert base64 to image
-	imgReader := b64.NewDecoder(b64.StdEncoding, strings.NewReader(base64))
-	img, _, err := image.Decode(imgReader)
-	if err != nil {
-		return nil, err
-	}
+// S3Storage stores objects in an S3 compatible bucket (e.g. Cloudflare R2)
+// served publicly from BaseURL
+type S3Storage struct {
+	Client  *s3.Client
+	Bucket  string
+	BaseURL string
+}
 
-	resizeImage := imaging.Resize(img, 1000, 0, imaging.Lanczos)
+func NewS3Storage(client *s3.Client, bucket string, baseURL string) *S3Storage {
+	return &S3Storage{Client: client, Bucket: bucket, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
 
-	var jpegImage bytes.Buffer
+func (s *S3Storage) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	ctx, cancel := context.WithTimeout(ctx, putTimeout)
+	defer cancel()
 
-	err = jpeg.Encode(&jpegImage, resizeImage, &jpeg.Options{
-		Quality: 85,
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Body:        body,
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
 	})
-	if err != nil {
-		return nil, err
-	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
+	return err
+}
 
-	_, err = client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(BUCKET_NAME),
-		Body:        &jpegImage,
-		Key:         aws.String(fmt.Sprintf("image/%v", fileName)),
-		ContentType: aws.String("image"),
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
 	})
 
-	if err != nil {
-		return nil, err
+	return err
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	result := []Object{}
+
+	pages := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range page.Contents {
+			result = append(result, Object{
+				Key:     aws.ToString(o.Key),
+				ModTime: aws.ToTime(o.LastModified),
+			})
+		}
 	}
 
-	publicURL := fmt.Sprintf("https://%v/image/%v", IMAGE_LOCATION, fileName)
+	return result, nil
+}
+
+func (s *S3Storage) PublicURL(key string) string {
+	return fmt.Sprintf("%v/%v", s.BaseURL, key)
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	presign := s3.NewPresignClient(s.Client)
+
+	request, err := presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+
+	if err != nil {
+		return "", err
+	}
 
-	return &publicURL, nil
+	return request.URL, nil
 }
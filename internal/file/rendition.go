@@ -0,0 +1,161 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"image"
+	"image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/disintegration/imaging"
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+const jpegQuality = 85
+
+// MainRendition is the rendition used as the plain image URL, it has the
+// size every image had before renditions existed
+const MainRendition = "detail"
+
+// Rendition is a resized copy made of every uploaded image, images narrower
+// than Width are never upscaled
+type Rendition struct {
+	Name  string
+	Width int
+}
+
+var DefaultRenditions []Rendition = []Rendition{
+	{Name: "thumbnail", Width: 160},
+	{Name: "card", Width: 400},
+	{Name: MainRendition, Width: 1000},
+	{Name: "zoom", Width: 2000},
+}
+
+// uploadRendition resizes img to r and uploads it as JPEG and WebP under
+// image/<prefix>/<name>.jpg and .webp
+func uploadRendition(ctx context.Context, storage Storage, img image.Image, prefix string, r Rendition) (*domain.ImageRendition, error) {
+	width := min(r.Width, img.Bounds().Dx())
+	resized := imaging.Resize(img, width, 0, imaging.Lanczos)
+
+	var jpegImage bytes.Buffer
+
+	err := jpeg.Encode(&jpegImage, resized, &jpeg.Options{
+		Quality: jpegQuality,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var webpImage bytes.Buffer
+
+	err = nativewebp.Encode(&webpImage, resized, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	jpegKey := fmt.Sprintf("image/%v/%v.jpg", prefix, r.Name)
+	webpKey := fmt.Sprintf("image/%v/%v.webp", prefix, r.Name)
+
+	err = storage.Put(ctx, jpegKey, "image/jpeg", &jpegImage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = storage.Put(ctx, webpKey, "image/webp", &webpImage)
+	if err != nil {
+		storage.Delete(context.WithoutCancel(ctx), jpegKey)
+		return nil, err
+	}
+
+	return &domain.ImageRendition{
+		Name:   r.Name,
+		Width:  resized.Bounds().Dx(),
+		Height: resized.Bounds().Dy(),
+		JPEG:   storage.PublicURL(jpegKey),
+		WebP:   storage.PublicURL(webpKey),
+	}, nil
+}
+
+// UploadImageRenditions uploads every rendition of img in parallel, in the
+// order of renditions. When one of them fails the others are deleted.
+func UploadImageRenditions(ctx context.Context, storage Storage, img image.Image, prefix string, renditions []Rendition) ([]*domain.ImageRendition, error) {
+	result := make([]*domain.ImageRendition, len(renditions))
+	errs := make([]error, len(renditions))
+
+	var wg sync.WaitGroup
+
+	for i, r := range renditions {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			result[i], errs[i] = uploadRendition(ctx, storage, img, prefix, r)
+		}()
+	}
+
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		uploaded := []string{}
+
+		for _, r := range result {
+			if r != nil {
+				uploaded = append(uploaded, r.JPEG, r.WebP)
+			}
+		}
+
+		DeleteObjects(context.WithoutCancel(ctx), storage, uploaded)
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteObjects deletes the objects served at urls, urls that aren't served
+// by storage (e.g. images hosted elsewhere) are skipped
+func DeleteObjects(ctx context.Context, storage Storage, urls []string) error {
+	errs := []error{}
+
+	for _, url := range urls {
+		key, ok := KeyOf(storage, url)
+
+		if !ok || key == "" {
+			continue
+		}
+
+		errs = append(errs, storage.Delete(ctx, key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// MainURL returns the JPEG of the main rendition, or of the widest one when
+// it isn't configured
+func MainURL(renditions []*domain.ImageRendition) string {
+	var main *domain.ImageRendition
+
+	for _, r := range renditions {
+		if r.Name == MainRendition {
+			return r.JPEG
+		}
+
+		if main == nil || r.Width > main.Width {
+			main = r
+		}
+	}
+
+	if main == nil {
+		return ""
+	}
+
+	return main.JPEG
+}
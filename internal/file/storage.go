@@ -0,0 +1,36 @@
+package image
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Object is a stored object as listed by Storage.List
+type Object struct {
+	Key     string
+	ModTime time.Time
+}
+
+// Storage keeps the uploaded objects, keys are slash separated paths like
+// "image/<gear id>/<image id>/card.jpg"
+type Storage interface {
+	// Put creates or replaces the object at key
+	Put(ctx context.Context, key string, contentType string, body io.Reader) error
+	// Delete removes the object at key, deleting a missing object is not an
+	// error
+	Delete(ctx context.Context, key string) error
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// PublicURL is where the object is served to everyone
+	PublicURL(key string) string
+	// SignedURL gives a temporary access to the object until expires passed
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// KeyOf returns the key of the object served at url, ok is false when url
+// isn't served by storage
+func KeyOf(storage Storage, url string) (key string, ok bool) {
+	return strings.CutPrefix(url, storage.PublicURL(""))
+}
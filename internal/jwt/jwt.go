@@ -93,6 +93,9 @@ func GenerateRefreshToken(u *domain.UserInfo) (string, error) {
 		"first_name": u.FirstName,
 		"last_name":  u.LastName,
 		"phone":      u.Phone,
+		"role":       u.Role,
+
+		"session_version": u.SessionVersion,
 	}, REFRESH_TOKEN_SECRET, time.Second*2592000)
 }
 
@@ -111,6 +114,7 @@ func GenerateAccessToken(rt RefrestToken) (string, error) {
 		"first_name": claims["first_name"],
 		"last_name":  claims["last_name"],
 		"phone":      claims["phone"],
+		"role":       claims["role"],
 	}, ACCESS_TOKEN_SECRET, time.Second*300)
 }
 
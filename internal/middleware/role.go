@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthorizedConfig struct {
+	Permissions []domain.Permission
+}
+
+// AuthorizedWithConfig must run after AuthenticatedWithConfig, it relies on
+// the "user" set in the context to check the role permissions
+func AuthorizedWithConfig(co *AuthorizedConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			u, ok := c.Get("user").(*domain.UserInfo)
+
+			if u == nil || !ok {
+				return c.JSON(http.StatusUnauthorized, &domain.Response{
+					Message: "You are not logged in",
+				})
+			}
+
+			for _, p := range co.Permissions {
+				if !u.Role.Can(p) {
+					return c.JSON(http.StatusForbidden, &domain.Response{
+						Message: "You do not have permission to access this resource",
+					})
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"slices"
@@ -10,18 +11,64 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+type UserRepository interface {
+	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+}
+
+// SessionUser sets the "user" of the session in the context, read again from
+// the database so a role change applies at once. A session issued before the
+// user's session version was raised is ended.
+func SessionUser(r UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userInfo, err := session.IsAuth(c)
+
+			if err != nil {
+				c.Set("auth_error", err)
+				return next(c)
+			}
+
+			user, err := r.GetUserByID(c.Request().Context(), userInfo.ID.String())
+
+			if err != nil {
+				c.Set("auth_error", fmt.Errorf("user not existed"))
+				return next(c)
+			}
+
+			if user.SessionVersion != userInfo.SessionVersion {
+				c.Set("auth_error", fmt.Errorf("session ended, log in again"))
+				return next(c)
+			}
+
+			c.Set("user", &domain.UserInfo{
+				ID:        user.ID,
+				Username:  user.Username,
+				Email:     user.Email,
+				FirstName: user.FirstName,
+				LastName:  user.LastName,
+				Phone:     user.Phone,
+				Role:      user.Role,
+
+				SessionVersion: user.SessionVersion,
+			})
+
+			return next(c)
+		}
+	}
+}
+
 type AuthenticatedConfig struct {
 	Excludes []string
 }
 
+// AuthenticatedWithConfig must run after SessionUser, it relies on the "user"
+// set in the context
 func AuthenticatedWithConfig(co *AuthenticatedConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Try to get user info first
-			userInfo, err := session.IsAuth(c)
+			userInfo, ok := c.Get("user").(*domain.UserInfo)
 
-			if userInfo != nil {
-				c.Set("user", userInfo)
+			if userInfo != nil && ok {
 				return next(c)
 			}
 
@@ -29,6 +76,12 @@ func AuthenticatedWithConfig(co *AuthenticatedConfig) echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			err, ok := c.Get("auth_error").(error)
+
+			if !ok {
+				err = fmt.Errorf("no session")
+			}
+
 			return c.JSON(http.StatusUnauthorized, &domain.Response{
 				Message: fmt.Sprintf("You are not logged in. Detail: %v", err.Error()),
 			})
@@ -3,9 +3,6 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
-	"reflect"
-	"strings"
 
 	"github.com/goldenfealla/gear-manager/domain"
 	"github.com/google/uuid"
@@ -22,6 +19,10 @@ func NewAddressRepository(conn *pgxpool.Pool) *AddressRepository {
 }
 
 func (r *AddressRepository) GetAddressByID(ctx context.Context, id string) (*domain.Address, error) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
 	query := `
 		SELECT * FROM address WHERE id=@id
 	`
@@ -30,10 +31,14 @@ func (r *AddressRepository) GetAddressByID(ctx context.Context, id string) (*dom
 		"id": id,
 	}
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
 
 	address, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Address])
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +55,7 @@ func (r *AddressRepository) GetAddressList(ctx context.Context, userID string) (
 		"user_id": userID,
 	}
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
 
 	addresses, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Address])
 
@@ -76,7 +81,7 @@ func (r *AddressRepository) AddAddress(ctx context.Context, userID string, a *do
 		"user_id": userID,
 	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 
 	if err != nil {
 		return err
@@ -86,43 +91,15 @@ func (r *AddressRepository) AddAddress(ctx context.Context, userID string, a *do
 }
 
 func (r *AddressRepository) UpdateAddress(ctx context.Context, id string, a *domain.UpdateAddressForm) error {
-	args := pgx.NamedArgs{
-		"id": id,
-	}
-
-	// iterate through struct to get field need to update
-	v := reflect.ValueOf(*a)
-	typeOfG := v.Type()
+	b := newUpdateBuilder("address", "address", "country")
 
-	fieldString := []string{}
-
-	for i := 0; i < v.NumField(); i++ {
-		field := typeOfG.Field(i).Tag.Get("db")
-		value := v.Field(i)
-
-		if !value.IsNil() {
-			args[field] = value.Elem()
-			fieldString = append(fieldString, fmt.Sprintf("%v='%v'", field, value.Elem()))
-		}
-	}
-
-	if len(fieldString) == 0 {
-		return errors.New("field to update is required")
-	}
-
-	query := fmt.Sprintf(`
-		UPDATE address
-		SET %v
-		WHERE id=@id;
-	`, strings.Join(fieldString, ","))
-
-	_, err := r.Conn.Exec(ctx, query, args)
+	err := b.Form(a)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return b.Exec(ctx, conn(ctx, r.Conn), id)
 }
 
 func (r *AddressRepository) DeleteAddress(ctx context.Context, id string) error {
@@ -134,7 +111,7 @@ func (r *AddressRepository) DeleteAddress(ctx context.Context, id string) error
 		"id": id,
 	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 
 	if err != nil {
 		return err
@@ -0,0 +1,336 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BuildRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewBuildRepository(conn *pgxpool.Pool) *BuildRepository {
+	return &BuildRepository{Conn: conn}
+}
+
+// getBuildSlots loads the slots of builds and computes their total
+func (r *BuildRepository) getBuildSlots(ctx context.Context, builds []*domain.Build) error {
+	ids := make([]uuid.UUID, len(builds))
+	byID := make(map[uuid.UUID]*domain.Build, len(builds))
+
+	for i, b := range builds {
+		ids[i] = b.ID
+		byID[b.ID] = b
+		b.Slots = []*domain.BuildSlot{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			BuildSlot.build_id,
+			BuildSlot.slot,
+			BuildSlot.quantity,
+			Gear.id,
+			Gear.name,
+			Gear.type,
+			Gear.price,
+			Gear.discount,
+			Gear.quantity,
+			Gear.image_url,
+			Gear.brand,
+			Gear.variety,
+			Gear.specs,
+			Gear.variant_axes,
+			Gear.archived_at,
+			Gear.promotion
+		FROM build_slot BuildSlot
+		JOIN %v ON BuildSlot.gear_id=Gear.id
+		WHERE BuildSlot.build_id=ANY(@ids)
+		ORDER BY BuildSlot.slot
+	`, promotedGear)
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
+	if err != nil {
+		return err
+	}
+
+	_, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.BuildSlot, error) {
+		var buildID uuid.UUID
+		var gear domain.Gear
+		var slot domain.BuildSlot
+
+		err := row.Scan(
+			&buildID,
+			&slot.Slot,
+			&slot.Quantity,
+			&gear.ID,
+			&gear.Name,
+			&gear.Type,
+			&gear.Price,
+			&gear.Discount,
+			&gear.Quantity,
+			&gear.ImageURL,
+			&gear.Brand,
+			&gear.Variety,
+			&gear.Specs,
+			&gear.VariantAxes,
+			&gear.ArchivedAt,
+			&gear.Promotion,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		slot.Gear = &gear
+		b := byID[buildID]
+		b.Slots = append(b.Slots, &slot)
+
+		return &slot, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, b := range builds {
+		b.ComputeTotal()
+	}
+
+	return nil
+}
+
+func (r *BuildRepository) getBuild(ctx context.Context, where string, args pgx.NamedArgs) (*domain.Build, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, share_token FROM build WHERE %v
+	`, where)
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	build, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Build])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.getBuildSlots(ctx, []*domain.Build{build})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return build, nil
+}
+
+func (r *BuildRepository) GetBuildByID(ctx context.Context, id string) (*domain.Build, error) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return r.getBuild(ctx, "id=@id", pgx.NamedArgs{
+		"id": id,
+	})
+}
+
+func (r *BuildRepository) GetBuildByShareToken(ctx context.Context, token string) (*domain.Build, error) {
+	return r.getBuild(ctx, "share_token=@token", pgx.NamedArgs{
+		"token": token,
+	})
+}
+
+func (r *BuildRepository) GetBuildList(ctx context.Context, userID string) ([]*domain.Build, error) {
+	query := `
+		SELECT id, user_id, name, share_token FROM build
+		WHERE user_id=@user_id
+		ORDER BY id DESC
+	`
+
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	builds, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Build])
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.getBuildSlots(ctx, builds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return builds, nil
+}
+
+func (r *BuildRepository) AddBuild(ctx context.Context, userID uuid.UUID, f *domain.AddBuildForm) (uuid.UUID, error) {
+	query := `
+		INSERT INTO build (id, user_id, name)
+		VALUES (@id, @user_id, @name)
+	`
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	args := pgx.NamedArgs{
+		"id":      newUUID,
+		"user_id": userID,
+		"name":    f.Name,
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return newUUID, nil
+}
+
+// CloneBuild copies the build and its slots to a new unshared build of
+// userID
+func (r *BuildRepository) CloneBuild(ctx context.Context, id uuid.UUID, userID uuid.UUID, name string) (uuid.UUID, error) {
+	query := `
+		WITH cloned AS (
+			INSERT INTO build (id, user_id, name)
+			VALUES (@new_id, @user_id, @name)
+			RETURNING id
+		)
+		INSERT INTO build_slot (build_id, slot, gear_id, quantity)
+		SELECT cloned.id, slot, gear_id, quantity
+		FROM build_slot, cloned
+		WHERE build_id=@id
+	`
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	args := pgx.NamedArgs{
+		"id":      id,
+		"new_id":  newUUID,
+		"user_id": userID,
+		"name":    name,
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return newUUID, nil
+}
+
+func (r *BuildRepository) UpdateBuild(ctx context.Context, id string, f *domain.UpdateBuildForm) error {
+	b := newUpdateBuilder("build", "name")
+
+	err := b.Form(f)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Exec(ctx, conn(ctx, r.Conn), id)
+}
+
+// SetBuildShareToken shares the build under token, a nil token stops sharing
+func (r *BuildRepository) SetBuildShareToken(ctx context.Context, id string, token *string) error {
+	b := newUpdateBuilder("build", "share_token")
+
+	err := b.Set("share_token", token)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Exec(ctx, conn(ctx, r.Conn), id)
+}
+
+// SetBuildSlot puts the gear in the slot, replacing what it held
+func (r *BuildRepository) SetBuildSlot(ctx context.Context, id uuid.UUID, slot string, gearID uuid.UUID, quantity int64) error {
+	query := `
+		INSERT INTO build_slot (build_id, slot, gear_id, quantity)
+		VALUES (@build_id, @slot, @gear_id, @quantity)
+		ON CONFLICT (build_id, slot) DO UPDATE
+		SET gear_id=EXCLUDED.gear_id, quantity=EXCLUDED.quantity
+	`
+
+	args := pgx.NamedArgs{
+		"build_id": id,
+		"slot":     slot,
+		"gear_id":  gearID,
+		"quantity": quantity,
+	}
+
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *BuildRepository) RemoveBuildSlot(ctx context.Context, id uuid.UUID, slot string) error {
+	query := `
+		DELETE FROM build_slot
+		WHERE build_id=@build_id AND slot=@slot
+	`
+
+	args := pgx.NamedArgs{
+		"build_id": id,
+		"slot":     slot,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *BuildRepository) DeleteBuild(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM build
+		WHERE id=@id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
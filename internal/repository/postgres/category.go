@@ -0,0 +1,556 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// categoryCacheTTL bounds how long another instance's category changes take
+// to show up, changes made through this instance are visible right away
+const categoryCacheTTL = time.Minute
+
+var nonCodeChar = regexp.MustCompile(`[^A-Z0-9]+`)
+
+type categoryTree struct {
+	list       []*domain.Category
+	byID       map[uuid.UUID]*domain.Category
+	byKey      map[string]*domain.Category
+	byCode     map[string]*domain.Category
+	children   map[uuid.UUID][]*domain.Category
+	attributes map[uuid.UUID][]*domain.Attribute
+	loadedAt   time.Time
+}
+
+// ancestors returns the parents of c from the root down, followed by c
+func (t *categoryTree) ancestors(c *domain.Category) []*domain.Category {
+	result := []*domain.Category{c}
+
+	for c.ParentID != nil {
+		c = t.byID[*c.ParentID]
+		result = append([]*domain.Category{c}, result...)
+	}
+
+	return result
+}
+
+// categoryAttributes returns a copy of the attributes of categories, the
+// first attribute wins when a key is used more than once
+func (t *categoryTree) categoryAttributes(categories []*domain.Category) []*domain.Attribute {
+	result := []*domain.Attribute{}
+	seen := map[string]bool{}
+
+	for _, c := range categories {
+		for _, a := range t.attributes[c.ID] {
+			if seen[a.Key] {
+				continue
+			}
+
+			seen[a.Key] = true
+			aa := *a
+			result = append(result, &aa)
+		}
+	}
+
+	return result
+}
+
+// subtree returns c and all of its descendants
+func (t *categoryTree) subtree(c *domain.Category) []*domain.Category {
+	result := []*domain.Category{c}
+
+	for _, child := range t.children[c.ID] {
+		result = append(result, t.subtree(child)...)
+	}
+
+	return result
+}
+
+type CategoryRepository struct {
+	Conn *pgxpool.Pool
+
+	mu    sync.RWMutex
+	cache *categoryTree
+}
+
+func NewCategoryRepository(conn *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{Conn: conn}
+}
+
+func (r *CategoryRepository) load(ctx context.Context) (*categoryTree, error) {
+	query := `
+		SELECT id, key, code, name, parent_id, position
+		FROM category
+		ORDER BY position, name
+	`
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query)
+
+	list, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Category])
+
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT id, category_id, key, name, type, unit, options, required, position
+		FROM category_attribute
+		ORDER BY position, name
+	`
+
+	rows, _ = conn(ctx, r.Conn).Query(ctx, query)
+
+	attributes, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Attribute])
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := &categoryTree{
+		list:       list,
+		byID:       make(map[uuid.UUID]*domain.Category, len(list)),
+		byKey:      make(map[string]*domain.Category, len(list)),
+		byCode:     make(map[string]*domain.Category, len(list)),
+		children:   make(map[uuid.UUID][]*domain.Category),
+		attributes: make(map[uuid.UUID][]*domain.Attribute),
+		loadedAt:   time.Now(),
+	}
+
+	for _, a := range attributes {
+		t.attributes[a.CategoryID] = append(t.attributes[a.CategoryID], a)
+	}
+
+	for _, c := range list {
+		t.byID[c.ID] = c
+		t.byKey[c.Key] = c
+		t.byCode[c.Code] = c
+
+		if c.ParentID != nil {
+			t.children[*c.ParentID] = append(t.children[*c.ParentID], c)
+		}
+	}
+
+	return t, nil
+}
+
+// tree returns the cached categories, reloading them once they are stale.
+// The returned tree is shared and must not be modified.
+func (r *CategoryRepository) tree(ctx context.Context) (*categoryTree, error) {
+	r.mu.RLock()
+	t := r.cache
+	r.mu.RUnlock()
+
+	if t != nil && time.Since(t.loadedAt) < categoryCacheTTL {
+		return t, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cache != nil && time.Since(r.cache.loadedAt) < categoryCacheTTL {
+		return r.cache, nil
+	}
+
+	t, err := r.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache = t
+
+	return t, nil
+}
+
+func (r *CategoryRepository) invalidate() {
+	r.mu.Lock()
+	r.cache = nil
+	r.mu.Unlock()
+}
+
+// GetCategoryTree returns the root categories with their children filled
+func (r *CategoryRepository) GetCategoryTree(ctx context.Context) ([]*domain.Category, error) {
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var build func(parent *uuid.UUID) []*domain.Category
+	build = func(parent *uuid.UUID) []*domain.Category {
+		result := []*domain.Category{}
+
+		for _, c := range t.list {
+			if (parent == nil && c.ParentID != nil) || (parent != nil && (c.ParentID == nil || *c.ParentID != *parent)) {
+				continue
+			}
+
+			node := *c
+			node.Children = build(&c.ID)
+			result = append(result, &node)
+		}
+
+		return result
+	}
+
+	return build(nil), nil
+}
+
+func (r *CategoryRepository) GetCategoryByKey(ctx context.Context, key string) (*domain.Category, error) {
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := t.byKey[strings.ToLower(key)]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	result := *c
+
+	return &result, nil
+}
+
+// HasCategory reports whether key is a stored category
+func (r *CategoryRepository) HasCategory(ctx context.Context, key string) bool {
+	_, err := r.GetCategoryByKey(ctx, key)
+
+	return err == nil
+}
+
+// GetCategoryCodes returns the code of the category key and of all its
+// subcategories. It returns nil for domain.AllCategory, which must not
+// filter anything.
+func (r *CategoryRepository) GetCategoryCodes(ctx context.Context, key string) ([]string, error) {
+	key = strings.ToLower(key)
+
+	if key == domain.AllCategory {
+		return nil, nil
+	}
+
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := t.byKey[key]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	codes := []string{}
+
+	for _, sub := range t.subtree(c) {
+		codes = append(codes, sub.Code)
+	}
+
+	return codes, nil
+}
+
+// GetCategoryList returns every stored category, ordered by position
+func (r *CategoryRepository) GetCategoryList(ctx context.Context) ([]*domain.Category, error) {
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Category, len(t.list))
+
+	for i, c := range t.list {
+		cc := *c
+		result[i] = &cc
+	}
+
+	return result, nil
+}
+
+// IsDescendant reports whether the category key is id or one of its
+// descendants, it is used to refuse moves that would create a cycle
+func (r *CategoryRepository) IsDescendant(ctx context.Context, key string, id uuid.UUID) (bool, error) {
+	r.invalidate()
+
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	c, ok := t.byKey[strings.ToLower(key)]
+
+	if !ok {
+		return false, domain.ErrNotFound
+	}
+
+	for _, sub := range t.subtree(c) {
+		if sub.ID == id {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *CategoryRepository) AddCategory(ctx context.Context, f *domain.AddCategoryForm, parentID *uuid.UUID) error {
+	query := `
+		INSERT INTO category (id, key, code, name, parent_id, position)
+		VALUES (@id, @key, @code, @name, @parent_id, @position)
+	`
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return err
+	}
+
+	args := pgx.NamedArgs{
+		"id":        newUUID,
+		"key":       f.Key,
+		"code":      strings.Trim(nonCodeChar.ReplaceAllString(strings.ToUpper(f.Key), "_"), "_"),
+		"name":      f.Name,
+		"parent_id": parentID,
+		"position":  f.Position,
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+// UpdateCategory updates the form fields, parent is only changed when
+// setParent is true so a nil parentID can move the category to the root
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, id uuid.UUID, f *domain.UpdateCategoryForm, setParent bool, parentID *uuid.UUID) error {
+	b := newUpdateBuilder("category", "name", "position", "parent_id")
+
+	err := b.Form(f)
+
+	if err != nil {
+		return err
+	}
+
+	if setParent {
+		err = b.Set("parent_id", parentID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = b.Exec(ctx, conn(ctx, r.Conn), id.String())
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+// DeleteCategory refuses to delete a category that still has subcategories
+// or gear, they must be moved first
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM category
+		WHERE id=@id
+			AND NOT EXISTS (SELECT 1 FROM category WHERE parent_id=@id)
+			AND NOT EXISTS (SELECT 1 FROM gear WHERE type=(SELECT code FROM category WHERE id=@id))
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	if tag.RowsAffected() == 0 {
+		return errors.New("category still has subcategories or gear")
+	}
+
+	return nil
+}
+
+// CategoryKeyOf maps a gear.type code back to its category key
+func (r *CategoryRepository) CategoryKeyOf(ctx context.Context, code string) (string, error) {
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	c, ok := t.byCode[code]
+
+	if !ok {
+		return "", fmt.Errorf("category code %v not exist", code)
+	}
+
+	return c.Key, nil
+}
+
+// GetCategoryAttributes returns the attributes a gear of the category key
+// has, the inherited ones first
+func (r *CategoryRepository) GetCategoryAttributes(ctx context.Context, key string) ([]*domain.Attribute, error) {
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := t.byKey[strings.ToLower(key)]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return t.categoryAttributes(t.ancestors(c)), nil
+}
+
+// GetFilterAttributes returns the attributes the gear listed under the
+// category key can be filtered by, which also covers the subcategories.
+// domain.AllCategory covers every attribute.
+func (r *CategoryRepository) GetFilterAttributes(ctx context.Context, key string) ([]*domain.Attribute, error) {
+	key = strings.ToLower(key)
+
+	t, err := r.tree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key == domain.AllCategory {
+		return t.categoryAttributes(t.list), nil
+	}
+
+	c, ok := t.byKey[key]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return t.categoryAttributes(append(t.ancestors(c), t.subtree(c)[1:]...)), nil
+}
+
+func (r *CategoryRepository) AddAttribute(ctx context.Context, categoryID uuid.UUID, f *domain.AddAttributeForm) error {
+	query := `
+		INSERT INTO category_attribute (id, category_id, key, name, type, unit, options, required, position)
+		VALUES (@id, @category_id, @key, @name, @type, @unit, @options, @required, @position)
+	`
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return err
+	}
+
+	options := f.Options
+
+	if options == nil {
+		options = []string{}
+	}
+
+	args := pgx.NamedArgs{
+		"id":          newUUID,
+		"category_id": categoryID,
+		"key":         f.Key,
+		"name":        f.Name,
+		"type":        f.Type,
+		"unit":        f.Unit,
+		"options":     options,
+		"required":    f.Required,
+		"position":    f.Position,
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+func (r *CategoryRepository) UpdateAttribute(ctx context.Context, id uuid.UUID, f *domain.UpdateAttributeForm) error {
+	b := newUpdateBuilder("category_attribute", "name", "unit", "options", "required", "position")
+
+	err := b.Form(f)
+
+	if err != nil {
+		return err
+	}
+
+	err = b.Exec(ctx, conn(ctx, r.Conn), id.String())
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+// DeleteAttribute deletes the attribute and removes its value from the specs
+// of the gear in codes
+func (r *CategoryRepository) DeleteAttribute(ctx context.Context, id uuid.UUID, codes []string) error {
+	query := `
+		WITH deleted AS (
+			DELETE FROM category_attribute
+			WHERE id=@id
+			RETURNING key
+		), stripped AS (
+			UPDATE gear
+			SET specs=specs - deleted.key
+			FROM deleted
+			WHERE type=ANY(@codes)
+		)
+		SELECT count(*) FROM deleted
+	`
+
+	args := pgx.NamedArgs{
+		"id":    id,
+		"codes": codes,
+	}
+
+	var count int64
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&count)
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
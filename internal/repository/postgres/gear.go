@@ -4,41 +4,117 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/goldenfealla/gear-manager/domain"
 	f "github.com/goldenfealla/gear-manager/internal/file"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// gearColumns are the columns scanned into domain.Gear, gear has other
+// columns (e.g. search_vector) so never select it with *. They are read from
+// promotedGear.
+var gearColumns = `id, sku, name, type, price, discount, quantity, image_url, image_renditions, brand, variety, specs, variant_axes, archived_at, promotion, ` + gearLowestPrice
+
+// gearLowestPrice is the lowest discounted price of the gear in the last
+// domain.LowestPriceDays days. Every old value recorded in the window was in
+// effect during it, with the current price they cover the whole window.
+// Promotions are left out, the history doesn't record them and the price
+// before a sale must still show during it.
+var gearLowestPrice = fmt.Sprintf(`least(
+	(price * (1 - discount / 100))::float8,
+	(
+		SELECT min(h.old_price*(1-h.old_discount/100)) FROM gear_history h
+		WHERE h.gear_id=gear.id AND h.variant_id IS NULL
+			AND h.changed_at >= now() - interval '%v days'
+	)
+) AS lowest_price`, domain.LowestPriceDays)
+
+// promotedGear is gear with the columns of its running promotion, read in
+// place of the gear table. The running promotions and the category keys
+// above every category are resolved once per query, then matched to each
+// gear. It is the only place the running promotion of a gear is resolved.
+const promotedGear = `(
+	WITH RECURSIVE running AS MATERIALIZED (
+		SELECT * FROM promotion
+		WHERE starts_at <= now() AND now() < ends_at
+	), ancestor AS (
+		SELECT code, id, parent_id, key FROM category
+		UNION ALL
+		SELECT a.code, c.id, c.parent_id, c.key FROM category c JOIN ancestor a ON c.id = a.parent_id
+	), category_keys AS (
+		SELECT code, array_agg(key::text) AS keys FROM ancestor GROUP BY code
+	)
+	SELECT
+		gear.*,
+		coalesce(p.discount, 0) AS promotion_discount,
+		CASE WHEN p.id IS NOT NULL THEN
+			jsonb_build_object('id', p.id, 'name', p.name, 'discount', p.discount, 'ends_at', p.ends_at)
+		END AS promotion
+	FROM gear
+	LEFT JOIN category_keys ck ON ck.code = gear.type
+	LEFT JOIN LATERAL (
+		SELECT r.* FROM running r
+		WHERE gear.id = ANY(r.gear_ids)
+			OR gear.brand = ANY(r.brands)
+			OR 'all' = ANY(r.categories)
+			OR r.categories && ck.keys
+		ORDER BY r.priority DESC, r.discount DESC, r.created_at, r.id
+		LIMIT 1
+	) p ON true
+) gear`
+
+// searchQuery parses the "q" filter with the same configuration as the
+// gear.search_vector column
+const searchQuery = `websearch_to_tsquery('simple', @q)`
+
 type GearRepository struct {
-	Conn     *pgxpool.Pool
-	S3Client *s3.Client
+	Conn       *pgxpool.Pool
+	Storage    f.Storage
+	Categories *CategoryRepository
+	// Renditions are made of every uploaded image
+	Renditions []f.Rendition
 }
 
-func NewGearRepository(conn *pgxpool.Pool, s3Client *s3.Client) *GearRepository {
-	return &GearRepository{Conn: conn, S3Client: s3Client}
+func NewGearRepository(conn *pgxpool.Pool, storage f.Storage, categories *CategoryRepository, renditions []f.Rendition) *GearRepository {
+	return &GearRepository{Conn: conn, Storage: storage, Categories: categories, Renditions: renditions}
 }
 
-func (r *GearRepository) getGearFilterList(ctx context.Context, category string, field string) ([]string, error) {
-	query := fmt.Sprintf("SELECT DISTINCT %v FROM gear WHERE type=@type", field)
+// categoryCodes resolves a category key to the gear.type codes it covers,
+// subcategories included
+func (r *GearRepository) categoryCodes(ctx context.Context, key string) ([]string, error) {
+	codes, err := r.Categories.GetCategoryCodes(ctx, key)
 
-	key := strings.ToLower(category)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, &domain.FilterError{Param: "category", Message: fmt.Sprintf("has unknown category '%v'", key)}
+	}
 
-	if _, ok := domain.GearTypeMap[key]; !ok {
-		return nil, errors.New("category not exist")
+	return codes, err
+}
+
+func (r *GearRepository) getGearFilterList(ctx context.Context, category string, field string) ([]string, error) {
+	codes, err := r.categoryCodes(ctx, category)
+
+	if err != nil {
+		return nil, err
 	}
 
-	args := pgx.NamedArgs{
-		"type": domain.GearTypeMap[key],
+	where := "WHERE archived_at IS NULL"
+	args := pgx.NamedArgs{}
+
+	if codes != nil {
+		args["type"] = codes
+		where += " AND type=ANY(@type)"
 	}
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	query := fmt.Sprintf("SELECT DISTINCT %v FROM gear %v", field, where)
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
 
 	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (string, error) {
 		var item string
@@ -61,68 +137,415 @@ func (r *GearRepository) GetGearVarietyList(ctx context.Context, category string
 	return r.getGearFilterList(ctx, category, "variety")
 }
 
-func (r *GearRepository) processWhereFilter(args pgx.NamedArgs, filter domain.ListGearFilter) (*string, error) {
-	key := strings.ToLower(*filter.Category)
+// suggestionThreshold is the minimum trigram word similarity for a value to
+// be suggested, low enough to catch typos like "geforse" or "ryzn". It is
+// set as pg_trgm.word_similarity_threshold for the name and brand filters.
+const suggestionThreshold = 0.3
 
-	if _, ok := domain.GearTypeMap[key]; !ok {
-		return nil, errors.New("category not exist")
+// GetGearSuggestion must run within a transaction, see suggestionThreshold
+func (r *GearRepository) GetGearSuggestion(ctx context.Context, filter domain.SuggestGearFilter) ([]*domain.GearSuggestion, error) {
+	categories, err := r.Categories.GetCategoryList(ctx)
+
+	if err != nil {
+		return nil, err
 	}
 
-	w := []string{}
+	keys := make([]string, len(categories))
+	types := make([]string, len(categories))
+	names := make([]string, len(categories))
+	typeKeys := make(map[string]string, len(categories))
+
+	for i, c := range categories {
+		keys[i] = c.Key
+		types[i] = c.Code
+		names[i] = c.Name
+		typeKeys[c.Code] = c.Key
+	}
+
+	args := pgx.NamedArgs{
+		"q":         filter.Query,
+		"limit":     *filter.Limit,
+		"threshold": suggestionThreshold,
+		"keys":      keys,
+		"types":     types,
+		"names":     names,
+		"name":      domain.SuggestionName,
+		"brand":     domain.SuggestionBrand,
+		"category":  domain.SuggestionCategory,
+	}
+
+	gearWhere := ""
+	categoryWhere := ""
+
+	if filter.Category != nil {
+		codes, err := r.categoryCodes(ctx, *filter.Category)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if codes != nil {
+			args["type"] = codes
+			gearWhere = "AND type=ANY(@type)"
+			categoryWhere = "AND c.type=ANY(@type)"
+		}
+	}
+
+	// the <% operator filters with the trigram indexes, its threshold is set
+	// for the surrounding transaction only. word_similarity just ranks.
+	_, err = conn(ctx, r.Conn).Exec(ctx, `
+		SELECT set_config('pg_trgm.word_similarity_threshold', @threshold::text, true)
+	`, pgx.NamedArgs{"threshold": suggestionThreshold})
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT kind, value, category, gear_id, score FROM (
+			SELECT
+				@name::text AS kind,
+				name AS value,
+				type AS category,
+				id AS gear_id,
+				word_similarity(@q, name) AS score,
+				row_number() OVER (PARTITION BY type ORDER BY word_similarity(@q, name) DESC) AS n
+			FROM gear
+			WHERE @q <%% name AND archived_at IS NULL %[1]v
+
+			UNION ALL
+
+			SELECT
+				@brand::text,
+				brand,
+				type,
+				NULL::uuid,
+				max(word_similarity(@q, brand)),
+				row_number() OVER (PARTITION BY type ORDER BY max(word_similarity(@q, brand)) DESC)
+			FROM gear
+			WHERE @q <%% brand AND archived_at IS NULL %[1]v
+			GROUP BY brand, type
+
+			UNION ALL
+
+			SELECT
+				@category::text,
+				c.key,
+				c.type,
+				NULL::uuid,
+				greatest(similarity(@q, c.key), word_similarity(@q, c.name)),
+				1
+			FROM unnest(@keys::text[], @types::text[], @names::text[]) AS c(key, type, name)
+			WHERE greatest(similarity(@q, c.key), word_similarity(@q, c.name)) >= @threshold %[2]v
+		) suggestion
+		WHERE n <= @limit
+		ORDER BY score DESC, value
+	`, gearWhere, categoryWhere)
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	suggestions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.GearSuggestion])
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the database stores the type code, respond with the category key
+	for _, s := range suggestions {
+		s.Category = typeKeys[s.Category]
+	}
+
+	return suggestions, nil
+}
+
+// gearCondition is one condition of the gear WHERE clause, tagged with the
+// facet it filters so facet counts can leave it out
+type gearCondition struct {
+	facet string
+	expr  string
+}
+
+const (
+	facetBrand    = "brand"
+	facetVariety  = "variety"
+	facetPrice    = "price"
+	facetDiscount = "discount"
+	facetStock    = "stock"
+)
+
+// nonEmpty drops the blank values of a repeated query param
+func nonEmpty(values []string) []string {
+	result := []string{}
+
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
 
-	if key != "all" {
-		args["category"] = domain.GearTypeMap[key]
-		w = append(w, "type=@category")
+	return result
+}
+
+// processRangeFilter adds the conditions of an inclusive min/max range of
+// the expr value
+func processRangeFilter(args pgx.NamedArgs, w []gearCondition, facet string, expr string, min *float64, max *float64) ([]gearCondition, error) {
+	if min != nil && *min < 0 {
+		return nil, &domain.FilterError{Param: "min_" + facet, Message: "must not be negative"}
+	}
+
+	if max != nil && *max < 0 {
+		return nil, &domain.FilterError{Param: "max_" + facet, Message: "must not be negative"}
+	}
+
+	if min != nil && max != nil && *min > *max {
+		return nil, &domain.FilterError{Param: "min_" + facet, Message: fmt.Sprintf("must not be greater than 'max_%v'", facet)}
+	}
+
+	if min != nil {
+		args["min_"+facet] = *min
+		w = append(w, gearCondition{facet, fmt.Sprintf("%v>=@min_%v", expr, facet)})
+	}
+
+	if max != nil {
+		args["max_"+facet] = *max
+		w = append(w, gearCondition{facet, fmt.Sprintf("%v<=@max_%v", expr, facet)})
+	}
+
+	return w, nil
+}
+
+// processLegacyPriceFilter parses the "start,end" price param
+func processLegacyPriceFilter(args pgx.NamedArgs, w []gearCondition, price string) ([]gearCondition, error) {
+	prices := strings.Split(price, ",")
+
+	if len(prices) != 2 {
+		return nil, &domain.FilterError{Param: "price", Message: "must be formatted as 'start,end'"}
+	}
+
+	startPrice, err := strconv.ParseInt(strings.TrimSpace(prices[0]), 10, 64)
+
+	if err != nil {
+		return nil, &domain.FilterError{Param: "price", Message: "has an invalid start"}
+	}
+
+	endPrice, err := strconv.ParseInt(strings.TrimSpace(prices[1]), 10, 64)
+
+	if err != nil {
+		return nil, &domain.FilterError{Param: "price", Message: "has an invalid end"}
+	}
+
+	if startPrice != -1 {
+		args["start_price"] = startPrice
+		w = append(w, gearCondition{facetPrice, "price>@start_price"})
+	}
+
+	if endPrice != -1 {
+		args["end_price"] = endPrice
+		w = append(w, gearCondition{facetPrice, "price<@end_price"})
+	}
+
+	return w, nil
+}
+
+// processFilterConditions turns the filter into WHERE conditions. Values of
+// the same facet are OR-ed, different facets are AND-ed. List, count and
+// facets all go through here so they always agree.
+func (r *GearRepository) processFilterConditions(ctx context.Context, args pgx.NamedArgs, filter domain.ListGearFilter) ([]gearCondition, error) {
+	if filter.Category == nil {
+		return nil, &domain.FilterError{Param: "category", Message: "is required"}
+	}
+
+	codes, err := r.categoryCodes(ctx, *filter.Category)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if filter.Brand != nil {
-		args["brand"] = *filter.Brand
-		w = append(w, "brand=@brand")
+	// archived gear is only listed on its own, see ListGearFilter.Archived
+	w := []gearCondition{{"archived", "archived_at IS NULL"}}
+
+	if filter.Archived {
+		w = []gearCondition{{"archived", "archived_at IS NOT NULL"}}
 	}
 
-	if filter.Variety != nil {
-		args["variety"] = *filter.Variety
-		w = append(w, "variety=@variety")
+	if codes != nil {
+		args["category"] = codes
+		w = append(w, gearCondition{"category", "type=ANY(@category)"})
+	}
+
+	if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
+		args["q"] = strings.TrimSpace(*filter.Query)
+		w = append(w, gearCondition{"q", fmt.Sprintf("search_vector @@ %v", searchQuery)})
+	}
+
+	if brands := nonEmpty(filter.Brand); len(brands) > 0 {
+		args["brand"] = brands
+		w = append(w, gearCondition{facetBrand, "brand=ANY(@brand)"})
+	}
+
+	if varieties := nonEmpty(filter.Variety); len(varieties) > 0 {
+		args["variety"] = varieties
+		w = append(w, gearCondition{facetVariety, "variety=ANY(@variety)"})
 	}
 
 	if filter.Price != nil {
-		prices := strings.Split(*filter.Price, ",")
+		if filter.MinPrice != nil || filter.MaxPrice != nil {
+			return nil, &domain.FilterError{Param: "price", Message: "can't be used with 'min_price' or 'max_price'"}
+		}
 
-		startPrice, err := strconv.ParseInt(prices[0], 10, 64)
+		w, err = processLegacyPriceFilter(args, w, *filter.Price)
 
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	w, err = processRangeFilter(args, w, facetPrice, "price", filter.MinPrice, filter.MaxPrice)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a gear on sale has the discount of its promotion
+	w, err = processRangeFilter(args, w, facetDiscount, effectiveDiscount, filter.MinDiscount, filter.MaxDiscount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.InStock != nil && *filter.InStock {
+		w = append(w, gearCondition{facetStock, "quantity>0"})
+	}
+
+	return r.processSpecFilter(ctx, args, w, *filter.Category, filter.Specs)
+}
+
+// specFacet is the facet of the conditions on the attribute key
+func specFacet(key string) string {
+	return "spec." + key
+}
+
+// specNumber reads the spec arg as a number, values that aren't numbers are
+// NULL instead of failing the cast
+func specNumber(arg string) string {
+	return fmt.Sprintf("CASE WHEN jsonb_typeof(specs->@%[1]v)='number' THEN (specs->>@%[1]v)::float8 END", arg)
+}
+
+// processSpecFilter adds the conditions on the gear specs. The attribute keys
+// are sent as arguments so they never reach the SQL text.
+func (r *GearRepository) processSpecFilter(ctx context.Context, args pgx.NamedArgs, w []gearCondition, category string, specs map[string]*domain.SpecFilter) ([]gearCondition, error) {
+	if len(specs) == 0 {
+		return w, nil
+	}
+
+	attributes, err := r.Categories.GetFilterAttributes(ctx, category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*domain.Attribute, len(attributes))
+
+	for _, a := range attributes {
+		byKey[a.Key] = a
+	}
+
+	// sorted so the same filter always builds the same query
+	keys := make([]string, 0, len(specs))
+
+	for key := range specs {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for i, key := range keys {
+		sf := specs[key]
+		param := specFacet(key)
+		a, ok := byKey[key]
+
+		if !ok {
+			return nil, &domain.FilterError{Param: param, Message: "is not an attribute of this category"}
+		}
 
-		if startPrice != -1 {
-			args["start_price"] = startPrice
-			w = append(w, "price>@start_price")
+		arg := fmt.Sprintf("spec_%v", i)
+		args[arg] = key
+
+		if values := nonEmpty(sf.Values); len(values) > 0 {
+			parsed := make([]string, len(values))
+
+			for j, v := range values {
+				parsed[j], err = a.ParseFilterValue(v)
+
+				if err != nil {
+					return nil, &domain.FilterError{Param: param, Message: fmt.Sprintf("has invalid value '%v'", v)}
+				}
+			}
+
+			args[arg+"_values"] = parsed
+			w = append(w, gearCondition{param, fmt.Sprintf("specs->>@%[1]v=ANY(@%[1]v_values)", arg)})
 		}
 
-		endPrice, err := strconv.ParseInt(prices[1], 10, 64)
+		if sf.Min == nil && sf.Max == nil {
+			continue
+		}
 
-		if err != nil {
-			return nil, err
+		if a.Type != domain.AttributeNumber {
+			return nil, &domain.FilterError{Param: param, Message: "has no range, it isn't a number attribute"}
+		}
+
+		if sf.Min != nil && sf.Max != nil && *sf.Min > *sf.Max {
+			return nil, &domain.FilterError{Param: param + ".min", Message: fmt.Sprintf("must not be greater than '%v.max'", param)}
+		}
+
+		if sf.Min != nil {
+			args[arg+"_min"] = *sf.Min
+			w = append(w, gearCondition{param, fmt.Sprintf("%v>=@%v_min", specNumber(arg), arg)})
 		}
 
-		if endPrice != -1 {
-			args["end_price"] = endPrice
-			w = append(w, "price<@end_price")
+		if sf.Max != nil {
+			args[arg+"_max"] = *sf.Max
+			w = append(w, gearCondition{param, fmt.Sprintf("%v<=@%v_max", specNumber(arg), arg)})
 		}
 	}
 
-	where := ""
-	if len(w) > 0 {
-		where = fmt.Sprintf("WHERE %v", strings.Join(w, " AND "))
+	return w, nil
+}
+
+// joinWhere builds the WHERE clause from conds, leaving out the conditions
+// of the facet exclude
+func joinWhere(conds []gearCondition, exclude string) string {
+	w := []string{}
+
+	for _, c := range conds {
+		if c.facet != exclude {
+			w = append(w, c.expr)
+		}
+	}
+
+	if len(w) == 0 {
+		return ""
 	}
 
+	return fmt.Sprintf("WHERE %v", strings.Join(w, " AND "))
+}
+
+func (r *GearRepository) processWhereFilter(ctx context.Context, args pgx.NamedArgs, filter domain.ListGearFilter) (*string, error) {
+	conds, err := r.processFilterConditions(ctx, args, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	where := joinWhere(conds, "")
+
 	return &where, nil
 }
 
 func (r *GearRepository) GetGearListCount(ctx context.Context, filter domain.ListGearFilter) (int64, error) {
 	args := pgx.NamedArgs{}
 
-	where, err := r.processWhereFilter(args, filter)
+	where, err := r.processWhereFilter(ctx, args, filter)
 
 	if err != nil {
 		return -1, err
@@ -130,13 +553,14 @@ func (r *GearRepository) GetGearListCount(ctx context.Context, filter domain.Lis
 
 	query := fmt.Sprintf(
 		`
-            SELECT count(id) FROM gear 
+            SELECT count(id) FROM %v
             %v
 		`,
+		promotedGear,
 		*where,
 	)
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
 
 	count, err := pgx.CollectOneRow(rows, func(row pgx.CollectableRow) (int64, error) {
 		var count int64
@@ -151,182 +575,628 @@ func (r *GearRepository) GetGearListCount(ctx context.Context, filter domain.Lis
 	return count, err
 }
 
-func (r *GearRepository) GetGearList(ctx context.Context, filter domain.ListGearFilter) ([]*domain.Gear, error) {
+// effectiveDiscount is the discount of the gear or the one of its running
+// promotion when bigger, see domain.Gear.EffectiveDiscount. It reads the
+// columns of promotedGear.
+const effectiveDiscount = `greatest(discount, promotion_discount)`
+
+// effectivePrice is the price after the effective discount percentage, cast
+// to float8 so its value survives the round trip through a cursor
+const effectivePrice = `(price * (1 - ` + effectiveDiscount + ` / 100))::float8`
+
+// gearSales joins the number of units sold per gear, carts are not sales
+const gearSales = `
+	LEFT JOIN LATERAL (
+		SELECT coalesce(sum(OrderGear.quantity), 0) AS sold
+		FROM gear_order OrderGear
+		JOIN "order" ON "order".id=OrderGear.order_id
+		WHERE OrderGear.gear_id=gear.id AND "order".status<>@cart_status
+	) sales ON true
+`
+
+type gearSort struct {
+	columns []sortColumn
+	// join is added to the FROM clause for keys that aren't gear columns
+	join string
+	// search is set for keys that need the "q" param
+	search bool
+}
+
+// gearSortMap maps the "sort" query param to its keys. Ties are broken by
+// the following keys and the id is always the last one, so the order is
+// total and pages never shift.
+var gearSortMap map[string]gearSort = map[string]gearSort{
+	"": {
+		columns: []sortColumn{
+			{Expr: "id"},
+		},
+	},
+	// "asc" and "desc" sort by discount, they predate the named sorts
+	"asc": {
+		columns: []sortColumn{
+			{Expr: "discount::float8"},
+			{Expr: "id"},
+		},
+	},
+	"desc": {
+		columns: []sortColumn{
+			{Expr: "discount::float8", Desc: true},
+			{Expr: "id", Desc: true},
+		},
+	},
+	"price_asc": {
+		columns: []sortColumn{
+			{Expr: "price::float8"},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+	},
+	"price_desc": {
+		columns: []sortColumn{
+			{Expr: "price::float8", Desc: true},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+	},
+	"effective_price_asc": {
+		columns: []sortColumn{
+			{Expr: effectivePrice},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+	},
+	"effective_price_desc": {
+		columns: []sortColumn{
+			{Expr: effectivePrice, Desc: true},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+	},
+	"name_asc": {
+		columns: []sortColumn{
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+	},
+	"name_desc": {
+		columns: []sortColumn{
+			{Expr: "name", Desc: true},
+			{Expr: "id"},
+		},
+	},
+	// gear ids are UUIDv7 so they follow the creation time
+	"newest": {
+		columns: []sortColumn{
+			{Expr: "id", Desc: true},
+		},
+	},
+	"best_selling": {
+		columns: []sortColumn{
+			{Expr: "sales.sold", Desc: true},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+		join: gearSales,
+	},
+	"relevance": {
+		columns: []sortColumn{
+			{Expr: fmt.Sprintf("ts_rank(search_vector, %v)::float8", searchQuery), Desc: true},
+			{Expr: "name"},
+			{Expr: "id"},
+		},
+		search: true,
+	},
+}
+
+type gearKeysetRow struct {
+	domain.Gear
+	CursorKey []any `db:"cursor_key"`
+}
+
+// htmlEscape is the SQL expression escaping the HTML special characters of
+// the text expression expr
+func htmlEscape(expr string) string {
+	for _, r := range [][2]string{
+		{"&", "&amp;"},
+		{"<", "&lt;"},
+		{">", "&gt;"},
+		{`"`, "&quot;"},
+		{"'", "&#39;"},
+	} {
+		expr = fmt.Sprintf("replace(%v, '%v', '%v')", expr, strings.ReplaceAll(r[0], "'", "''"), r[1])
+	}
+
+	return expr
+}
+
+func (r *GearRepository) GetGearList(ctx context.Context, filter domain.ListGearFilter) (*domain.Page[*domain.Gear], error) {
 	args := pgx.NamedArgs{}
 
-	where, err := r.processWhereFilter(args, filter)
+	where, err := r.processWhereFilter(ctx, args, filter)
 
 	if err != nil {
 		return nil, err
 	}
 
-	sort := ""
+	_, searching := args["q"]
+
+	highlight := "NULL::text"
+
+	// the text is HTML-escaped first so the <mark> tags are the only markup
+	// a client rendering the highlight gets
+	if searching {
+		highlight = fmt.Sprintf(
+			`ts_headline('simple', %v, %v, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')`,
+			htmlEscape(`concat_ws(' ', name, brand, variety)`),
+			searchQuery,
+		)
+	}
+
+	sortName := ""
 
 	if filter.Sort != nil {
-		d := strings.ToUpper(*filter.Sort)
-		sort = fmt.Sprintf("ORDER BY discount %v", d)
+		sortName = strings.ToLower(*filter.Sort)
+	}
+
+	gs, ok := gearSortMap[sortName]
+
+	if !ok {
+		return nil, &domain.FilterError{Param: "sort", Message: fmt.Sprintf("has unknown sort '%v'", sortName)}
+	}
+
+	if gs.search && !searching {
+		return nil, &domain.FilterError{Param: "sort", Message: fmt.Sprintf("'%v' requires query param 'q'", sortName)}
+	}
+
+	if gs.join != "" {
+		args["cart_status"] = domain.CART
+	}
+
+	columns := gs.columns
+
+	var c *cursor
+	offset := int64(0)
+
+	if filter.Cursor != nil {
+		c, err = decodeCursor(*filter.Cursor, sortName, columns)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keyset := keysetWhere(columns, c, args)
+
+		if *where == "" {
+			*where = fmt.Sprintf("WHERE %v", keyset)
+		} else {
+			*where = fmt.Sprintf("%v AND %v", *where, keyset)
+		}
+	} else if filter.Page != nil {
+		offset = (*filter.Limit) * (*filter.Page - 1)
 	}
 
 	query := fmt.Sprintf(
 		`
-            SELECT * FROM gear 
+            SELECT %v, %v AS highlight, %v FROM %v
+            %v
             %v
             %v
             LIMIT %v OFFSET %v
 		`,
+		gearColumns,
+		highlight,
+		keysetSelect(columns),
+		promotedGear,
+		gs.join,
 		*where,
-		sort,
-		*filter.Limit,
-		(*filter.Limit)*(*filter.Page-1),
+		keysetOrder(columns, c != nil && c.Backward),
+		*filter.Limit+1,
+		offset,
 	)
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[gearKeysetRow])
+
+	if err != nil {
+		return nil, err
+	}
+
+	gears := make([]*domain.Gear, len(result))
+	keys := make([][]any, len(result))
+
+	for i, row := range result {
+		gears[i] = &row.Gear
+		keys[i] = row.CursorKey
+	}
+
+	err = r.getGearImageList(ctx, gears)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keysetPage(gears, keys, sortName, *filter.Limit, c, offset > 0), nil
+}
+
+// getGearFacetValues counts the gear per value of expr, leaving out the
+// conditions of facet
+func (r *GearRepository) getGearFacetValues(ctx context.Context, args pgx.NamedArgs, conds []gearCondition, facet string, expr string) ([]*domain.FacetValue, error) {
+	query := fmt.Sprintf(`
+		SELECT %[1]v AS value, count(id) AS count
+		FROM %[3]v
+		%[2]v
+		GROUP BY %[1]v
+		ORDER BY count DESC, value
+	`, expr, joinWhere(conds, facet), promotedGear)
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	values, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.FacetValue])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetGearFacets counts the gear matching filter for every brand, variety
+// and price bucket. Each facet is counted under all the other filters but its
+// own, so the values stay selectable when one of them is already picked.
+// buckets are the sorted lower bounds of the price buckets, the last bucket
+// has no upper bound.
+func (r *GearRepository) GetGearFacets(ctx context.Context, filter domain.ListGearFilter, buckets []float64) (*domain.GearFacets, error) {
+	args := pgx.NamedArgs{}
+
+	conds, err := r.processFilterConditions(ctx, args, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	brand, err := r.getGearFacetValues(ctx, args, conds, facetBrand, "brand")
 
-	gears, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Gear])
+	if err != nil {
+		return nil, err
+	}
+
+	variety, err := r.getGearFacetValues(ctx, args, conds, facetVariety, "variety")
+
+	if err != nil {
+		return nil, err
+	}
+
+	mins := make([]float64, len(buckets))
+	maxs := make([]*float64, len(buckets))
+
+	for i := range buckets {
+		mins[i] = buckets[i]
+
+		if i+1 < len(buckets) {
+			maxs[i] = &buckets[i+1]
+		}
+	}
+
+	args["bucket_mins"] = mins
+	args["bucket_maxs"] = maxs
+
+	query := fmt.Sprintf(`
+		SELECT b.min, b.max, count(g.price) AS count
+		FROM unnest(@bucket_mins::float8[], @bucket_maxs::float8[]) AS b(min, max)
+		LEFT JOIN (
+			SELECT price FROM %v
+			%v
+		) g ON g.price >= b.min AND (b.max IS NULL OR g.price < b.max)
+		GROUP BY b.min, b.max
+		ORDER BY b.min
+	`, promotedGear, joinWhere(conds, facetPrice))
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	price, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.PriceBucket])
+
+	if err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`
+		SELECT count(id) FROM %v
+		%v
+	`, promotedGear, joinWhere(conds, ""))
+
+	var total int64
+	err = conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&total)
+
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := r.getGearSpecFacets(ctx, args, conds, *filter.Category)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return gears, err
+	return &domain.GearFacets{
+		Total:   total,
+		Brand:   brand,
+		Variety: variety,
+		Price:   price,
+		Specs:   specs,
+	}, nil
+}
+
+// getGearSpecFacets counts the gear per value of every attribute of category
+// but the free text ones, which have too many values to pick from
+func (r *GearRepository) getGearSpecFacets(ctx context.Context, args pgx.NamedArgs, conds []gearCondition, category string) ([]*domain.SpecFacet, error) {
+	result := []*domain.SpecFacet{}
+
+	if strings.ToLower(category) == domain.AllCategory {
+		return result, nil
+	}
+
+	attributes, err := r.Categories.GetFilterAttributes(ctx, category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attributes {
+		if a.Type == domain.AttributeString {
+			continue
+		}
+
+		args["facet_spec"] = a.Key
+		facetConds := append(slices.Clone(conds), gearCondition{"", "specs->>@facet_spec IS NOT NULL"})
+
+		values, err := r.getGearFacetValues(ctx, args, facetConds, specFacet(a.Key), "specs->>@facet_spec")
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &domain.SpecFacet{
+			Key:    a.Key,
+			Name:   a.Name,
+			Type:   a.Type,
+			Unit:   a.Unit,
+			Values: values,
+		})
+	}
+
+	return result, nil
 }
 
 func (r *GearRepository) GetGearByID(ctx context.Context, id string) (*domain.Gear, error) {
-	query := `
-		SELECT * FROM gear WHERE id=@id
-	`
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM %v WHERE id=@id
+	`, gearColumns, promotedGear)
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	rows, _ := r.Conn.Query(ctx, query, args)
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	gear, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[domain.Gear])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
 
-	gear, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Gear])
+	err = r.getGearImageList(ctx, []*domain.Gear{gear})
 
 	if err != nil {
 		return nil, err
 	}
 
+	if gear.HasVariants() {
+		gear.Variants, err = r.GetGearVariantList(ctx, id)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return gear, err
 }
 
-func (r *GearRepository) AddGear(ctx context.Context, g *domain.AddGearForm) error {
+// GetGearListByIDs returns the gear of ids in any order, it returns
+// domain.ErrNotFound when one of them doesn't exist
+func (r *GearRepository) GetGearListByIDs(ctx context.Context, ids []string) ([]*domain.Gear, error) {
+	// parsed before removing the duplicates, the same id may be sent in
+	// another case or form
+	unique := []uuid.UUID{}
+
+	for _, id := range ids {
+		gearUUID, err := uuid.Parse(id)
+
+		if err != nil {
+			return nil, domain.ErrNotFound
+		}
+
+		if !slices.Contains(unique, gearUUID) {
+			unique = append(unique, gearUUID)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM %v WHERE id=ANY(@ids::uuid[])
+	`, gearColumns, promotedGear)
+	args := pgx.NamedArgs{
+		"ids": unique,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	gears, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[domain.Gear])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gears) != len(unique) {
+		return nil, domain.ErrNotFound
+	}
+
+	return gears, nil
+}
+
+// GetGearListBySKUs returns the gear, archived included, having one of skus
+func (r *GearRepository) GetGearListBySKUs(ctx context.Context, skus []string) ([]*domain.Gear, error) {
+	query := fmt.Sprintf(`
+		SELECT %v FROM %v WHERE sku=ANY(@skus::text[])
+	`, gearColumns, promotedGear)
+	args := pgx.NamedArgs{
+		"skus": skus,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[domain.Gear])
+}
+
+// gearError maps the constraint violations of gear to domain errors
+func gearError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "gear_sku_key" {
+		return domain.ErrSKUTaken
+	}
+
+	return err
+}
+
+// AddGear adds the gear, keyed by its id when it has no sku. The image added
+// to its gallery is returned, see GearUsecase.AddGear.
+func (r *GearRepository) AddGear(ctx context.Context, g *domain.AddGearForm) (*domain.GearImage, error) {
 	query := `
-		INSERT INTO gear (id, name, type, price, discount, quantity, image_url, brand, variety) 
-		VALUES (@gearID, @gearName, @gearType, @gearPrice, @gearDiscount, @gearQuantity, @gearImageURL, @gearBrand, @gearVariety)
+		INSERT INTO gear (id, sku, name, type, price, discount, quantity, image_url, brand, variety, specs) 
+		VALUES (@gearID, coalesce(nullif(@gearSKU::text, ''), @gearID::text), @gearName, @gearType, @gearPrice, @gearDiscount, @gearQuantity, @gearImageURL, @gearBrand, @gearVariety, @gearSpecs)
 	`
 
 	newUUID, err := uuid.NewV7()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	key := strings.ToLower(g.Type)
+	category, err := r.Categories.GetCategoryByKey(ctx, g.Type)
+
+	if err != nil {
+		return nil, errors.New("category not exist")
+	}
 
 	args := pgx.NamedArgs{
 		"gearID":       newUUID,
+		"gearSKU":      g.SKU,
 		"gearName":     g.Name,
-		"gearType":     domain.GearTypeMap[key],
+		"gearType":     category.Code,
 		"gearPrice":    g.Price,
 		"gearDiscount": g.Discount,
 		"gearQuantity": g.Quantity,
 		"gearImageURL": "",
 		"gearBrand":    g.Brand,
 		"gearVariety":  g.Variety,
+		"gearSpecs":    g.Specs,
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return nil, gearError(err)
 	}
 
+	// the first image of the gallery becomes the primary image
 	if g.ImageBase64 != nil {
-		image_url, err := f.UploadImageJpeg(
-			r.S3Client,
-			*g.ImageBase64,
-			fmt.Sprintf("%v.jpg", newUUID.String()),
-		)
+		img, err := f.DecodeBase64Image(*g.ImageBase64)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		args["gearImageURL"] = *image_url
-	}
-
-	_, err = r.Conn.Exec(ctx, query, args)
-
-	if err != nil {
-		return err
+		return r.AddGearImage(ctx, newUUID.String(), &domain.NewGearImage{
+			Image: img,
+		})
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (r *GearRepository) UpdateGear(ctx context.Context, id string, g *domain.UpdateGearForm) error {
-	args := pgx.NamedArgs{
-		"id": id,
-	}
-
-	// iterate through struct to get field need to update
-	v := reflect.ValueOf(*g)
-	typeOfG := v.Type()
-
-	fieldString := []string{}
+// UpdateGear changes the gear. The image added to its gallery is returned,
+// even when making it primary fails, so its files can be deleted.
+func (r *GearRepository) UpdateGear(ctx context.Context, id string, g *domain.UpdateGearForm) (*domain.GearImage, error) {
+	b := newUpdateBuilder(
+		"gear",
+		"sku", "name", "type", "brand", "variety", "price", "discount", "quantity", "specs",
+	).Transform("type", func(v any) (any, error) {
+		category, err := r.Categories.GetCategoryByKey(ctx, v.(string))
 
-	for i := 0; i < v.NumField(); i++ {
-		field := typeOfG.Field(i).Tag.Get("db")
-		value := v.Field(i)
+		if err != nil {
+			return nil, errors.New("category not exist")
+		}
 
-		if !value.IsNil() {
-			if field == "image_base64" {
-				continue
-			}
+		return category.Code, nil
+	}).Transform("sku", func(v any) (any, error) {
+		// an empty sku resets it to the gear id
+		if v.(string) == "" {
+			gearUUID, err := uuid.Parse(id)
 
-			if field == "type" {
-				key := strings.ToLower(value.Elem().String())
-				val := domain.GearTypeMap[key]
-				args[field] = val
-				fieldString = append(fieldString, fmt.Sprintf("%v='%v'", field, val))
+			if err != nil {
+				return nil, domain.ErrNotFound
 			}
 
-			if field != "type" {
-				args[field] = value.Elem()
-				fieldString = append(fieldString, fmt.Sprintf("%v='%v'", field, value.Elem()))
-			}
+			return gearUUID.String(), nil
 		}
+
+		return v, nil
+	})
+
+	err := b.Form(g)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if g.ImageBase64 != nil {
-		imageURL, err := f.UploadImageJpeg(
-			r.S3Client,
-			*g.ImageBase64,
-			fmt.Sprintf("%v.jpg", id),
-		)
+	if g.ImageBase64 == nil || !b.Empty() {
+		err = b.Exec(ctx, conn(ctx, r.Conn), id)
 
 		if err != nil {
-			return err
+			return nil, gearError(err)
 		}
-
-		fieldString = append(fieldString, fmt.Sprintf("%v='%v'", "image_url", *imageURL))
-		args["gearImageURL"] = *imageURL
+	} else if _, err := r.GetGearByID(ctx, id); err != nil {
+		return nil, err
 	}
 
-	if len(fieldString) == 0 {
-		return errors.New("field to update is required")
-	}
+	// a new image is added to the gallery and made primary, the previous
+	// ones are kept
+	if g.ImageBase64 != nil {
+		img, err := f.DecodeBase64Image(*g.ImageBase64)
 
-	query := fmt.Sprintf(`
-		UPDATE gear
-		SET %v
-		WHERE id=@id;
-	`, strings.Join(fieldString, ","))
+		if err != nil {
+			return nil, err
+		}
 
-	fmt.Println(query)
+		image, err := r.AddGearImage(ctx, id, &domain.NewGearImage{
+			Image: img,
+		})
 
-	_, err := r.Conn.Exec(ctx, query, args)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return err
+		return image, r.SetPrimaryGearImage(ctx, id, image.ID.String())
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (r *GearRepository) UpdateGearQuantity(ctx context.Context, gearID string, quantity int64) error {
@@ -341,7 +1211,7 @@ func (r *GearRepository) UpdateGearQuantity(ctx context.Context, gearID string,
 		"quantity": quantity,
 	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
@@ -349,20 +1219,171 @@ func (r *GearRepository) UpdateGearQuantity(ctx context.Context, gearID string,
 	return nil
 }
 
-func (r *GearRepository) DeleteGear(ctx context.Context, id string) error {
+// DecreaseGearQuantity subtracts quantity from the stock only when enough is
+// left, so concurrent checkouts can never push the stock below zero. It
+// returns the stock it saw under the row lock, the one to report when it is
+// insufficient.
+func (r *GearRepository) DecreaseGearQuantity(ctx context.Context, gearID string, quantity int64) (int64, error) {
 	query := `
-		DELETE FROM gear
+		SELECT quantity, jsonb_array_length(variant_axes) > 0 FROM gear
 		WHERE id=@id
+		FOR UPDATE
 	`
+
+	args := pgx.NamedArgs{
+		"id":       gearID,
+		"quantity": quantity,
+	}
+
+	var available int64
+	var hasVariants bool
+
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&available, &hasVariants)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	// the stock of a gear with variants is theirs, see DecreaseVariantQuantity
+	if hasVariants {
+		return available, domain.ErrVariantRequired
+	}
+
+	if available < quantity {
+		return available, domain.ErrInsufficientStock
+	}
+
+	query = `
+		UPDATE gear
+		SET quantity=quantity-@quantity
+		WHERE id=@id AND quantity>=@quantity
+	`
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return available, domain.ErrInsufficientStock
+	}
+
+	return available, nil
+}
+
+// setGearArchivedAt archives or restores the gear, archiving keeps the time
+// the gear was first archived
+func (r *GearRepository) setGearArchivedAt(ctx context.Context, id string, expr string) error {
+	if err := uuid.Validate(id); err != nil {
+		return domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE gear
+		SET archived_at=%v
+		WHERE id=@id
+	`, expr)
+
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 
 	if err != nil {
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
 	return nil
 }
+
+func (r *GearRepository) ArchiveGear(ctx context.Context, id string) error {
+	return r.setGearArchivedAt(ctx, id, "coalesce(archived_at, now())")
+}
+
+func (r *GearRepository) RestoreGear(ctx context.Context, id string) error {
+	return r.setGearArchivedAt(ctx, id, "NULL")
+}
+
+// GetGearForUpdate locks the gear row until the surrounding transaction
+// ends, order lines can't be added for the gear meanwhile. Only the id and
+// the archive time are read.
+func (r *GearRepository) GetGearForUpdate(ctx context.Context, id string) (*domain.Gear, error) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := `
+		SELECT id, archived_at FROM gear WHERE id=@id FOR UPDATE
+	`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	gear, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[domain.Gear])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gear, nil
+}
+
+// IsGearOrdered reports whether any order or cart has a line of the gear
+func (r *GearRepository) IsGearOrdered(ctx context.Context, id string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM gear_order WHERE gear_id=@id)`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var ordered bool
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&ordered)
+
+	if err != nil {
+		return false, err
+	}
+
+	return ordered, nil
+}
+
+// DeleteGear removes the gear with its gallery for good and returns the
+// removed images, their stored files are kept (see DeleteImageObjects)
+func (r *GearRepository) DeleteGear(ctx context.Context, id string) ([]*domain.GearImage, error) {
+	// the select sees the gallery as it was before the cascade
+	query := fmt.Sprintf(`
+		WITH deleted AS (
+			DELETE FROM gear
+			WHERE id=@id
+			RETURNING id
+		)
+		SELECT %v FROM gear_image
+		WHERE gear_id IN (SELECT id FROM deleted)
+	`, gearImageColumns)
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	images, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.GearImage])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetBulkPriceTargets returns the current price and discount of the gear
+// matching the filter, or of their variants for gear with variants, as
+// changes to nothing. With lock the gear rows and their variant rows stay
+// locked until the end of the transaction.
+func (r *GearRepository) GetBulkPriceTargets(ctx context.Context, filter domain.ListGearFilter, lock bool) ([]*domain.BulkPriceChange, error) {
+	args := pgx.NamedArgs{}
+
+	where, err := r.processWhereFilter(ctx, args, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	locking := ""
+
+	if lock {
+		locking = "FOR UPDATE"
+
+		// the variants are locked before their gear, in the order a variant
+		// edit takes them, so the two don't deadlock
+		query := fmt.Sprintf(`
+			SELECT id FROM gear_variant
+			WHERE gear_id IN (SELECT id FROM %v %v)
+			ORDER BY id
+			FOR UPDATE
+		`, promotedGear, *where)
+
+		_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a gear with variant axes but no variant yet has nothing to change
+	query := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT id, sku, name, price, discount, variant_axes FROM gear
+			WHERE id IN (SELECT id FROM %v %v)
+			%v
+		)
+		SELECT
+			m.id AS gear_id,
+			v.id AS variant_id,
+			coalesce(v.sku, m.sku) AS sku,
+			m.name,
+			coalesce(v.price, m.price) AS old_price,
+			coalesce(v.discount, m.discount) AS old_discount,
+			coalesce(v.price, m.price) AS price,
+			coalesce(v.discount, m.discount) AS discount
+		FROM matched m
+		LEFT JOIN gear_variant v ON v.gear_id=m.id
+		WHERE v.id IS NOT NULL OR jsonb_array_length(m.variant_axes)=0
+		ORDER BY m.name, m.id, v.position, v.id
+	`, promotedGear, *where, locking)
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.BulkPriceChange])
+}
+
+// ApplyBulkPriceChanges sets the new values of changes, or the old ones
+// with undo. A gear or variant whose values aren't the expected ones anymore
+// is left alone, the applied changes are returned.
+func (r *GearRepository) ApplyBulkPriceChanges(ctx context.Context, changes []*domain.BulkPriceChange, undo bool) ([]*domain.BulkPriceChange, error) {
+	n := len(changes)
+	gearIDs := make([]uuid.UUID, n)
+	variantIDs := make([]*uuid.UUID, n)
+	fromPrices := make([]float64, n)
+	fromDiscounts := make([]float64, n)
+	toPrices := make([]float64, n)
+	toDiscounts := make([]float64, n)
+
+	for i, c := range changes {
+		gearIDs[i] = c.GearID
+		variantIDs[i] = c.VariantID
+		fromPrices[i], fromDiscounts[i] = c.OldPrice, c.OldDiscount
+		toPrices[i], toDiscounts[i] = c.Price, c.Discount
+
+		if undo {
+			fromPrices[i], toPrices[i] = toPrices[i], fromPrices[i]
+			fromDiscounts[i], toDiscounts[i] = toDiscounts[i], fromDiscounts[i]
+		}
+	}
+
+	query := `
+		WITH c AS (
+			SELECT * FROM unnest(
+				@gear_ids::uuid[], @variant_ids::uuid[],
+				@from_prices::float8[], @from_discounts::float8[],
+				@to_prices::float8[], @to_discounts::float8[]
+			) WITH ORDINALITY AS c(gear_id, variant_id, from_price, from_discount, to_price, to_discount, position)
+		), gears AS (
+			UPDATE gear
+			SET price=c.to_price, discount=c.to_discount
+			FROM c
+			WHERE c.variant_id IS NULL AND gear.id=c.gear_id
+				AND gear.price=c.from_price AND gear.discount=c.from_discount
+			RETURNING c.position
+		), variants AS (
+			UPDATE gear_variant
+			SET price=c.to_price, discount=c.to_discount
+			FROM c
+			WHERE gear_variant.id=c.variant_id AND gear_variant.gear_id=c.gear_id
+				AND gear_variant.price=c.from_price AND gear_variant.discount=c.from_discount
+			RETURNING c.position
+		)
+		SELECT position-1 FROM gears
+		UNION ALL SELECT position-1 FROM variants
+	`
+
+	args := pgx.NamedArgs{
+		"gear_ids":       gearIDs,
+		"variant_ids":    variantIDs,
+		"from_prices":    fromPrices,
+		"from_discounts": fromDiscounts,
+		"to_prices":      toPrices,
+		"to_discounts":   toDiscounts,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	positions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]*domain.BulkPriceChange, len(positions))
+	synced := map[uuid.UUID]bool{}
+
+	for i, p := range positions {
+		applied[i] = changes[p]
+	}
+
+	// the gear mirrors its cheapest variant
+	for _, c := range applied {
+		if c.VariantID == nil || synced[c.GearID] {
+			continue
+		}
+
+		synced[c.GearID] = true
+
+		err := r.syncVariantGear(ctx, c.GearID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return applied, nil
+}
+
+// AddBulkPriceOperation records op and its changes
+func (r *GearRepository) AddBulkPriceOperation(ctx context.Context, op *domain.BulkPriceOperation) error {
+	n := len(op.Changes)
+	gearIDs := make([]uuid.UUID, n)
+	variantIDs := make([]*uuid.UUID, n)
+	oldPrices := make([]float64, n)
+	oldDiscounts := make([]float64, n)
+	prices := make([]float64, n)
+	discounts := make([]float64, n)
+
+	for i, c := range op.Changes {
+		gearIDs[i] = c.GearID
+		variantIDs[i] = c.VariantID
+		oldPrices[i], oldDiscounts[i] = c.OldPrice, c.OldDiscount
+		prices[i], discounts[i] = c.Price, c.Discount
+	}
+
+	query := `
+		WITH operation AS (
+			INSERT INTO bulk_price_operation (id, rule, actor_id)
+			VALUES (@id::uuid, @rule::jsonb, @actor_id::uuid)
+			RETURNING id
+		)
+		INSERT INTO bulk_price_change (operation_id, gear_id, variant_id, old_price, old_discount, price, discount)
+		SELECT operation.id, c.*
+		FROM operation, unnest(
+			@gear_ids::uuid[], @variant_ids::uuid[],
+			@old_prices::float8[], @old_discounts::float8[],
+			@prices::float8[], @discounts::float8[]
+		) AS c
+	`
+
+	args := pgx.NamedArgs{
+		"id":            op.ID,
+		"rule":          op.Rule,
+		"actor_id":      op.ActorID,
+		"gear_ids":      gearIDs,
+		"variant_ids":   variantIDs,
+		"old_prices":    oldPrices,
+		"old_discounts": oldDiscounts,
+		"prices":        prices,
+		"discounts":     discounts,
+	}
+
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	return err
+}
+
+// GetBulkPriceOperation returns the operation with its changes
+func (r *GearRepository) GetBulkPriceOperation(ctx context.Context, id string) (*domain.BulkPriceOperation, error) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := `
+		SELECT id, rule, actor_id, created_at, undone_at,
+			(SELECT count(*) FROM bulk_price_change WHERE operation_id=@id) AS count
+		FROM bulk_price_operation
+		WHERE id=@id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	op, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.BulkPriceOperation])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT c.gear_id, c.variant_id, coalesce(v.sku, g.sku) AS sku, g.name,
+			c.old_price, c.old_discount, c.price, c.discount
+		FROM bulk_price_change c
+		JOIN gear g ON g.id=c.gear_id
+		LEFT JOIN gear_variant v ON v.id=c.variant_id
+		WHERE c.operation_id=@id
+		ORDER BY g.name, g.id, v.position, v.id
+	`
+
+	rows, _ = conn(ctx, r.Conn).Query(ctx, query, args)
+
+	op.Changes, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.BulkPriceChange])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// SetBulkPriceOperationUndone marks the operation undone, it returns
+// domain.ErrBulkUndone when it already is
+func (r *GearRepository) SetBulkPriceOperationUndone(ctx context.Context, id string) error {
+	if err := uuid.Validate(id); err != nil {
+		return domain.ErrNotFound
+	}
+
+	query := `
+		UPDATE bulk_price_operation
+		SET undone_at=now()
+		WHERE id=@id AND undone_at IS NULL
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBulkUndone
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const gearHistoryColumns = `id, gear_id, variant_id, price, discount, quantity, old_price, old_discount, old_quantity, actor_id, reason, changed_at`
+
+// gearHistorySortColumns lists the newest changes first
+var gearHistorySortColumns = []sortColumn{
+	{Expr: "id", Desc: true},
+}
+
+type gearHistoryKeysetRow struct {
+	domain.GearHistory
+	CursorKey []any `db:"cursor_key"`
+}
+
+func (r *GearRepository) GetGearHistory(ctx context.Context, filter domain.ListGearHistoryFilter) (*domain.Page[*domain.GearHistory], error) {
+	if err := uuid.Validate(filter.GearID); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	args := pgx.NamedArgs{
+		"gear_id": filter.GearID,
+		"limit":   filter.Limit + 1,
+		"offset":  int64(0),
+	}
+
+	where := "gear_id=@gear_id"
+
+	if filter.VariantID != nil {
+		if err := uuid.Validate(*filter.VariantID); err != nil {
+			return nil, domain.ErrNotFound
+		}
+
+		args["variant_id"] = *filter.VariantID
+		where += " AND variant_id=@variant_id"
+	}
+
+	var c *cursor
+	var err error
+
+	if filter.Cursor != nil {
+		c, err = decodeCursor(*filter.Cursor, "", gearHistorySortColumns)
+		if err != nil {
+			return nil, err
+		}
+
+		where = fmt.Sprintf("%v AND %v", where, keysetWhere(gearHistorySortColumns, c, args))
+	} else {
+		args["offset"] = (filter.Page - 1) * filter.Limit
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v, %v
+		FROM gear_history
+		WHERE %v
+		%v
+		LIMIT @limit OFFSET @offset
+	`, gearHistoryColumns, keysetSelect(gearHistorySortColumns), where, keysetOrder(gearHistorySortColumns, c != nil && c.Backward))
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[gearHistoryKeysetRow])
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*domain.GearHistory, len(result))
+	keys := make([][]any, len(result))
+
+	for i, row := range result {
+		history[i] = &row.GearHistory
+		keys[i] = row.CursorKey
+	}
+
+	return keysetPage(history, keys, "", filter.Limit, c, args["offset"].(int64) > 0), nil
+}
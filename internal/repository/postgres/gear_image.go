@@ -0,0 +1,352 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	f "github.com/goldenfealla/gear-manager/internal/file"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const gearImageColumns = `id, gear_id, url, alt_text, position, is_primary, renditions`
+
+// getGearImageList fills the gallery of gears
+func (r *GearRepository) getGearImageList(ctx context.Context, gears []*domain.Gear) error {
+	ids := make([]uuid.UUID, len(gears))
+	byID := make(map[uuid.UUID]*domain.Gear, len(gears))
+
+	for i, g := range gears {
+		ids[i] = g.ID
+		byID[g.ID] = g
+		g.Images = []*domain.GearImage{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM gear_image
+		WHERE gear_id=ANY(@ids)
+		ORDER BY position, id
+	`, gearImageColumns)
+
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	images, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.GearImage])
+
+	if err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		g := byID[img.GearID]
+		g.Images = append(g.Images, img)
+	}
+
+	return nil
+}
+
+func (r *GearRepository) GetGearImageList(ctx context.Context, gearID string) ([]*domain.GearImage, error) {
+	gear, err := r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gear.Images, nil
+}
+
+// AddGearImage uploads the image at the end of the gallery. The first image
+// of a gear becomes its primary image.
+func (r *GearRepository) AddGearImage(ctx context.Context, gearID string, img *domain.NewGearImage) (*domain.GearImage, error) {
+	gearUUID, err := uuid.Parse(gearID)
+
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return nil, err
+	}
+
+	renditions, err := f.UploadImageRenditions(
+		ctx,
+		r.Storage,
+		img.Image,
+		fmt.Sprintf("%v/%v", gearUUID, newUUID),
+		r.Renditions,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		WITH inserted AS (
+			INSERT INTO gear_image (id, gear_id, url, alt_text, position, is_primary, renditions)
+			SELECT @id::uuid, @gear_id::uuid, @url::text, @alt_text::text, coalesce(max(position)+1, 0), count(*)=0, @renditions::jsonb
+			FROM gear_image
+			WHERE gear_id=@gear_id
+			RETURNING %v
+		), promoted AS (
+			UPDATE gear
+			SET image_url=inserted.url, image_renditions=inserted.renditions
+			FROM inserted
+			WHERE gear.id=inserted.gear_id AND inserted.is_primary
+		)
+		SELECT * FROM inserted
+	`, gearImageColumns)
+
+	args := pgx.NamedArgs{
+		"id":         newUUID,
+		"gear_id":    gearUUID,
+		"url":        f.MainURL(renditions),
+		"alt_text":   img.AltText,
+		"renditions": renditions,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	image, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.GearImage])
+
+	if err != nil {
+		// nothing references the uploaded files
+		r.DeleteImageObjects(context.WithoutCancel(ctx), []*domain.GearImage{{
+			URL:        f.MainURL(renditions),
+			Renditions: renditions,
+		}})
+
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (r *GearRepository) UpdateGearImage(ctx context.Context, imageID string, img *domain.UpdateGearImageForm) error {
+	b := newUpdateBuilder("gear_image", "alt_text")
+
+	err := b.Form(img)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Exec(ctx, conn(ctx, r.Conn), imageID)
+}
+
+// SetPrimaryGearImage makes imageID the only primary image of the gear and
+// copies its URL to gear.image_url
+func (r *GearRepository) SetPrimaryGearImage(ctx context.Context, gearID string, imageID string) error {
+	if uuid.Validate(gearID) != nil || uuid.Validate(imageID) != nil {
+		return domain.ErrNotFound
+	}
+
+	query := `
+		WITH updated AS (
+			UPDATE gear_image
+			SET is_primary=(id=@image_id)
+			WHERE gear_id=@gear_id
+				AND EXISTS (SELECT 1 FROM gear_image WHERE id=@image_id AND gear_id=@gear_id)
+			RETURNING url, renditions, is_primary
+		)
+		UPDATE gear
+		SET image_url=updated.url, image_renditions=updated.renditions
+		FROM updated
+		WHERE gear.id=@gear_id AND updated.is_primary
+	`
+
+	args := pgx.NamedArgs{
+		"gear_id":  gearID,
+		"image_id": imageID,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// ReorderGearImages sets the position of every image to its index in
+// imageIDs
+func (r *GearRepository) ReorderGearImages(ctx context.Context, gearID string, imageIDs []string) error {
+	if err := uuid.Validate(gearID); err != nil {
+		return domain.ErrNotFound
+	}
+
+	query := `
+		UPDATE gear_image
+		SET position=o.position-1
+		FROM unnest(@ids::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE gear_image.id=o.id AND gear_image.gear_id=@gear_id
+	`
+
+	args := pgx.NamedArgs{
+		"gear_id": gearID,
+		"ids":     imageIDs,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() != int64(len(imageIDs)) {
+		return domain.ErrInvalidImageOrder
+	}
+
+	return nil
+}
+
+// DeleteGearImage removes the image from the gallery and returns it. When it
+// was the primary image the next one in order takes its place. The stored
+// files are kept, see DeleteImageObjects.
+func (r *GearRepository) DeleteGearImage(ctx context.Context, gearID string, imageID string) (*domain.GearImage, error) {
+	if uuid.Validate(gearID) != nil || uuid.Validate(imageID) != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		WITH deleted AS (
+			DELETE FROM gear_image
+			WHERE id=@image_id AND gear_id=@gear_id
+			RETURNING %v
+		), next AS (
+			SELECT id, url, renditions FROM gear_image
+			WHERE gear_id=@gear_id AND id<>@image_id
+				AND EXISTS (SELECT 1 FROM deleted WHERE is_primary)
+			ORDER BY position, id
+			LIMIT 1
+		), promoted AS (
+			UPDATE gear_image
+			SET is_primary=true
+			FROM next
+			WHERE gear_image.id=next.id
+		), replaced AS (
+			UPDATE gear
+			SET image_url=coalesce((SELECT url FROM next), ''),
+				image_renditions=coalesce((SELECT renditions FROM next), '[]')
+			WHERE id=@gear_id AND EXISTS (SELECT 1 FROM deleted WHERE is_primary)
+		)
+		SELECT * FROM deleted
+	`, gearImageColumns)
+
+	args := pgx.NamedArgs{
+		"gear_id":  gearID,
+		"image_id": imageID,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	image, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.GearImage])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// DeleteImageObjects deletes the stored files of images, call it once the
+// rows referencing them are gone for good (i.e. after the commit)
+func (r *GearRepository) DeleteImageObjects(ctx context.Context, images []*domain.GearImage) error {
+	urls := []string{}
+
+	for _, img := range images {
+		urls = append(urls, img.URLs()...)
+	}
+
+	return f.DeleteObjects(ctx, r.Storage, urls)
+}
+
+// imageReconcileGrace keeps the objects uploaded recently out of the
+// reconciliation, their row may not be committed yet
+const imageReconcileGrace = time.Hour
+
+// getImageURLs returns every image URL referenced by the database
+func (r *GearRepository) getImageURLs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT url FROM gear_image
+		UNION SELECT image_url FROM gear
+		UNION SELECT rendition->>'jpeg' FROM gear_image, jsonb_array_elements(renditions) rendition
+		UNION SELECT rendition->>'webp' FROM gear_image, jsonb_array_elements(renditions) rendition
+		UNION SELECT rendition->>'jpeg' FROM gear, jsonb_array_elements(image_renditions) rendition
+		UNION SELECT rendition->>'webp' FROM gear, jsonb_array_elements(image_renditions) rendition
+	`
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query)
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ReconcileImages compares the stored images to the ones referenced by the
+// database and deletes the unreferenced ones, unless dryRun is set. Objects
+// younger than imageReconcileGrace are skipped.
+func (r *GearRepository) ReconcileImages(ctx context.Context, dryRun bool) (*domain.ImageReconcileReport, error) {
+	// list the objects first, an image added in between is then referenced
+	// by the time the URLs are read
+	objects, err := r.Storage.List(ctx, "image/")
+
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := r.getImageURLs(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool, len(urls))
+
+	for _, url := range urls {
+		if key, ok := f.KeyOf(r.Storage, url); ok {
+			referenced[key] = true
+		}
+	}
+
+	report := &domain.ImageReconcileReport{
+		DryRun:  dryRun,
+		Checked: len(objects),
+		Orphans: []string{},
+		Failed:  []string{},
+	}
+
+	for _, o := range objects {
+		if referenced[o.Key] || time.Since(o.ModTime) < imageReconcileGrace {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, o.Key)
+
+		if dryRun {
+			continue
+		}
+
+		err := r.Storage.Delete(ctx, o.Key)
+
+		if err != nil {
+			report.Failed = append(report.Failed, o.Key)
+		}
+	}
+
+	return report, nil
+}
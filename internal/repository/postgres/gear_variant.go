@@ -0,0 +1,298 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const gearVariantColumns = `id, gear_id, sku, options, price, discount, quantity, image_id, position`
+
+// variantError maps the constraint violations of gear_variant to domain
+// errors
+func variantError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.ConstraintName {
+	case "gear_variant_sku_key":
+		return domain.ErrSKUTaken
+	case "gear_variant_gear_id_options_key":
+		return fmt.Errorf("%w: a variant with the same options already exists", domain.ErrInvalidVariant)
+	case "gear_order_variant_id_fkey":
+		return domain.ErrVariantInUse
+	}
+
+	return err
+}
+
+func (r *GearRepository) GetGearVariantList(ctx context.Context, gearID string) ([]*domain.GearVariant, error) {
+	if err := uuid.Validate(gearID); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM gear_variant
+		WHERE gear_id=@gear_id
+		ORDER BY position, id
+	`, gearVariantColumns)
+
+	args := pgx.NamedArgs{
+		"gear_id": gearID,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.GearVariant])
+}
+
+// GetGearVariant returns the variant variantID of the gear gearID
+func (r *GearRepository) GetGearVariant(ctx context.Context, gearID string, variantID string) (*domain.GearVariant, error) {
+	if uuid.Validate(gearID) != nil || uuid.Validate(variantID) != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM gear_variant
+		WHERE id=@id AND gear_id=@gear_id
+	`, gearVariantColumns)
+
+	args := pgx.NamedArgs{
+		"id":      variantID,
+		"gear_id": gearID,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	variant, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.GearVariant])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (r *GearRepository) SetVariantAxes(ctx context.Context, gearID string, axes []*domain.VariantAxis) error {
+	b := newUpdateBuilder("gear", "variant_axes")
+
+	err := b.Set("variant_axes", axes)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Exec(ctx, conn(ctx, r.Conn), gearID)
+}
+
+// syncVariantGear copies the cheapest variant and the total stock to the
+// gear so listing, filters and sorting keep working on the gear row. A gear
+// left without variants keeps its last values.
+func (r *GearRepository) syncVariantGear(ctx context.Context, gearID uuid.UUID) error {
+	query := `
+		WITH cheapest AS (
+			SELECT price, discount FROM gear_variant
+			WHERE gear_id=@gear_id
+			ORDER BY price*(1-discount/100), id
+			LIMIT 1
+		), stock AS (
+			SELECT sum(quantity) AS quantity FROM gear_variant
+			WHERE gear_id=@gear_id
+		)
+		UPDATE gear
+		SET price=cheapest.price, discount=cheapest.discount, quantity=stock.quantity
+		FROM cheapest, stock
+		WHERE gear.id=@gear_id
+	`
+
+	args := pgx.NamedArgs{
+		"gear_id": gearID,
+	}
+
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	return err
+}
+
+// AddGearVariant adds the variant at the end of the variants of the gear
+func (r *GearRepository) AddGearVariant(ctx context.Context, gearID string, f *domain.AddGearVariantForm) (*domain.GearVariant, error) {
+	gearUUID, err := uuid.Parse(gearID)
+
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO gear_variant (id, gear_id, sku, options, price, discount, quantity, image_id, position)
+		SELECT @id::uuid, @gear_id::uuid, @sku::text, @options::jsonb, @price::float8, @discount::float8,
+			@quantity::bigint, @image_id::uuid, coalesce(max(position)+1, 0)
+		FROM gear_variant
+		WHERE gear_id=@gear_id
+		RETURNING %v
+	`, gearVariantColumns)
+
+	args := pgx.NamedArgs{
+		"id":       newUUID,
+		"gear_id":  gearUUID,
+		"sku":      f.SKU,
+		"options":  f.Options,
+		"price":    f.Price,
+		"discount": f.Discount,
+		"quantity": f.Quantity,
+		"image_id": f.ImageID,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	variant, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.GearVariant])
+
+	if err != nil {
+		return nil, variantError(err)
+	}
+
+	err = r.syncVariantGear(ctx, gearUUID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (r *GearRepository) UpdateGearVariant(ctx context.Context, gearID string, variantID string, f *domain.UpdateGearVariantForm) error {
+	gearUUID, err := uuid.Parse(gearID)
+
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	b := newUpdateBuilder("gear_variant", "sku", "options", "price", "discount", "quantity", "image_id")
+
+	// an empty image_id unlinks the image
+	b.Transform("image_id", func(v any) (any, error) {
+		if v == "" {
+			return nil, nil
+		}
+
+		return v, nil
+	})
+
+	err = b.Form(f)
+
+	if err != nil {
+		return err
+	}
+
+	err = b.Exec(ctx, conn(ctx, r.Conn), variantID)
+
+	if err != nil {
+		return variantError(err)
+	}
+
+	return r.syncVariantGear(ctx, gearUUID)
+}
+
+func (r *GearRepository) DeleteGearVariant(ctx context.Context, gearID string, variantID string) error {
+	gearUUID, err := uuid.Parse(gearID)
+
+	if err != nil || uuid.Validate(variantID) != nil {
+		return domain.ErrNotFound
+	}
+
+	query := `
+		DELETE FROM gear_variant
+		WHERE id=@id AND gear_id=@gear_id
+	`
+
+	args := pgx.NamedArgs{
+		"id":      variantID,
+		"gear_id": gearUUID,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return variantError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return r.syncVariantGear(ctx, gearUUID)
+}
+
+// DecreaseVariantQuantity subtracts quantity from the stock of the variant
+// and of its gear, only when the variant has enough left. It returns the
+// stock of the variant it saw under the row lock.
+func (r *GearRepository) DecreaseVariantQuantity(ctx context.Context, gearID string, variantID string, quantity int64) (int64, error) {
+	query := `
+		SELECT quantity FROM gear_variant
+		WHERE id=@variant_id AND gear_id=@gear_id
+		FOR UPDATE
+	`
+
+	args := pgx.NamedArgs{
+		"gear_id":    gearID,
+		"variant_id": variantID,
+		"quantity":   quantity,
+	}
+
+	var available int64
+
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&available)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if available < quantity {
+		return available, domain.ErrInsufficientStock
+	}
+
+	query = `
+		WITH variant AS (
+			UPDATE gear_variant
+			SET quantity=quantity-@quantity
+			WHERE id=@variant_id AND gear_id=@gear_id AND quantity>=@quantity
+			RETURNING gear_id
+		)
+		UPDATE gear
+		SET quantity=gear.quantity-@quantity
+		FROM variant
+		WHERE gear.id=variant.gear_id
+	`
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return available, domain.ErrInsufficientStock
+	}
+
+	return available, nil
+}
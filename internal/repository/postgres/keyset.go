@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// sortColumn is one key of an ORDER BY. The last key of every sort mode must
+// be unique (the id) so the order is total and pages never overlap.
+type sortColumn struct {
+	Expr string
+	Desc bool
+}
+
+// cursor is the decoded form of the opaque next_cursor/prev_cursor tokens.
+// It keeps the sort mode it was issued for, so it can't be replayed with
+// another one, and the sort key values of the row it points at.
+type cursor struct {
+	Sort     string `json:"s"`
+	Values   []any  `json:"v"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func encodeCursor(c cursor) *string {
+	b, _ := json.Marshal(c)
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return &token
+}
+
+func decodeCursor(token string, sort string, columns []sortColumn) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c cursor
+	err = json.Unmarshal(b, &c)
+
+	if err != nil || c.Sort != sort || len(c.Values) != len(columns) {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// keysetSelect is the select expression collecting the sort key values of a
+// row, it's scanned into the cursor_key field of the row
+func keysetSelect(columns []sortColumn) string {
+	exprs := make([]string, len(columns))
+
+	for i, c := range columns {
+		exprs[i] = c.Expr
+	}
+
+	return fmt.Sprintf("json_build_array(%v) AS cursor_key", strings.Join(exprs, ", "))
+}
+
+// keysetOrder returns the ORDER BY clause, reversed when paging backward
+func keysetOrder(columns []sortColumn, backward bool) string {
+	o := make([]string, len(columns))
+
+	for i, c := range columns {
+		d := "ASC"
+		if c.Desc != backward {
+			d = "DESC"
+		}
+
+		o[i] = fmt.Sprintf("%v %v", c.Expr, d)
+	}
+
+	return fmt.Sprintf("ORDER BY %v", strings.Join(o, ", "))
+}
+
+// keysetWhere returns the condition selecting the rows after c in the sort
+// order. It's written as
+//
+//	(k0 > v0) OR (k0 = v0 AND k1 > v1) OR ...
+//
+// instead of a row comparison so every key can have its own direction.
+func keysetWhere(columns []sortColumn, c *cursor, args pgx.NamedArgs) string {
+	or := make([]string, len(columns))
+
+	for i, col := range columns {
+		and := []string{}
+
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%v = @cursor_%v", columns[j].Expr, j))
+		}
+
+		op := ">"
+		if col.Desc != c.Backward {
+			op = "<"
+		}
+
+		and = append(and, fmt.Sprintf("%v %v @cursor_%v", col.Expr, op, i))
+		or[i] = fmt.Sprintf("(%v)", strings.Join(and, " AND "))
+
+		args[fmt.Sprintf("cursor_%v", i)] = c.Values[i]
+	}
+
+	return fmt.Sprintf("(%v)", strings.Join(or, " OR "))
+}
+
+// keysetPage turns the rows fetched with a limit of limit+1 into a page.
+// keys holds the cursor_key of each row, c is the cursor of the request (nil
+// on the first page) and skipped tells whether rows before the first one
+// exist without a cursor, i.e. an OFFSET was used.
+func keysetPage[T any](items []T, keys [][]any, sort string, limit int64, c *cursor, skipped bool) *domain.Page[T] {
+	more := int64(len(items)) > limit
+
+	if more {
+		items = items[:limit]
+		keys = keys[:limit]
+	}
+
+	backward := c != nil && c.Backward
+
+	if backward {
+		slices.Reverse(items)
+		slices.Reverse(keys)
+	}
+
+	page := &domain.Page[T]{
+		Items: items,
+	}
+
+	if len(items) == 0 {
+		return page
+	}
+
+	hasNext := more
+	hasPrev := c != nil || skipped
+
+	if backward {
+		hasNext = true
+		hasPrev = more
+	}
+
+	if hasNext {
+		page.NextCursor = encodeCursor(cursor{Sort: sort, Values: keys[len(keys)-1]})
+	}
+
+	if hasPrev {
+		page.PrevCursor = encodeCursor(cursor{Sort: sort, Values: keys[0], Backward: true})
+	}
+
+	return page
+}
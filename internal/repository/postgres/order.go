@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/goldenfealla/gear-manager/domain"
 	"github.com/google/uuid"
@@ -26,29 +27,46 @@ func (r *OrderRepository) HasCart(ctx context.Context, userID string) bool {
 	}
 
 	var b bool
-	r.Conn.QueryRow(ctx, query, args).Scan(&b)
+	conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&b)
 
 	return b
 }
 
 func (r *OrderRepository) getOrderGearList(ctx context.Context, orderID uuid.UUID) ([]*domain.OrderGear, error) {
-	query := `
-		SELECT Gear.*, OrderGear.quantity
+	query := fmt.Sprintf(`
+		SELECT
+			Gear.id,
+			Gear.name,
+			Gear.type,
+			Gear.price,
+			Gear.discount,
+			Gear.quantity,
+			Gear.image_url,
+			Gear.brand,
+			Gear.variety,
+			Gear.variant_axes,
+			Gear.archived_at,
+			Gear.promotion,
+			CASE WHEN Variant.id IS NULL THEN NULL ELSE to_jsonb(Variant) END,
+			OrderGear.quantity
 		FROM "gear_order" OrderGear
-		JOIN "gear" Gear ON OrderGear.gear_id=Gear.id
+		JOIN %v ON OrderGear.gear_id=Gear.id
+		LEFT JOIN "gear_variant" Variant ON OrderGear.variant_id=Variant.id
 		WHERE order_id=@orderID
-	`
+		ORDER BY Gear.id, Variant.position, Variant.id
+	`, promotedGear)
 	args := &pgx.NamedArgs{
 		"orderID": orderID,
 	}
 
-	rows, err := r.Conn.Query(ctx, query, args)
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
 	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.OrderGear, error) {
 		var gear domain.Gear
+		var variant *domain.GearVariant
 		var quantity int64
 
 		err := row.Scan(
@@ -61,6 +79,10 @@ func (r *OrderRepository) getOrderGearList(ctx context.Context, orderID uuid.UUI
 			&gear.ImageURL,
 			&gear.Brand,
 			&gear.Variety,
+			&gear.VariantAxes,
+			&gear.ArchivedAt,
+			&gear.Promotion,
+			&variant,
 			&quantity,
 		)
 
@@ -70,6 +92,7 @@ func (r *OrderRepository) getOrderGearList(ctx context.Context, orderID uuid.UUI
 
 		return &domain.OrderGear{
 			Gear:     &gear,
+			Variant:  variant,
 			Quantity: quantity,
 		}, nil
 	})
@@ -82,7 +105,7 @@ func (r *OrderRepository) GetFullCartByUserID(ctx context.Context, userID string
 		"status":  domain.CART,
 	}
 
-	rows, err := r.Conn.Query(ctx, query, args)
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -106,17 +129,25 @@ func (r *OrderRepository) GetFullCartByUserID(ctx context.Context, userID string
 }
 
 func (r *OrderRepository) GetFullOrderByID(ctx context.Context, orderID string) (*domain.FullOrder, error) {
+	if err := uuid.Validate(orderID); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
 	query := `SELECT * FROM "order" WHERE id=@id`
 	args := &pgx.NamedArgs{
 		"id": orderID,
 	}
 
-	rows, err := r.Conn.Query(ctx, query, args)
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
 	order, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -134,32 +165,96 @@ func (r *OrderRepository) GetFullOrderByID(ctx context.Context, orderID string)
 	return fullOrder, nil
 }
 
-func (r *OrderRepository) GetFullOrderList(ctx context.Context, userID string, page int64, limit int64) ([]*domain.Order, error) {
-	query := `
-		SELECT * 
-		FROM "order" 
-		WHERE user_id=@user_id AND status<>@status
-		LIMIT @limit OFFSET @offset
-	`
+// GetOrderForUpdate locks the order row until the surrounding transaction ends
+func (r *OrderRepository) GetOrderForUpdate(ctx context.Context, orderID string) (*domain.Order, error) {
+	if err := uuid.Validate(orderID); err != nil {
+		return nil, domain.ErrNotFound
+	}
 
+	query := `SELECT * FROM "order" WHERE id=@id FOR UPDATE`
 	args := &pgx.NamedArgs{
+		"id": orderID,
+	}
+
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// orderSortColumns sorts orders newest first, order ids are UUIDv7 so they
+// follow the creation time
+var orderSortColumns = []sortColumn{
+	{Expr: "id", Desc: true},
+}
+
+type orderKeysetRow struct {
+	domain.Order
+	CursorKey []any `db:"cursor_key"`
+}
+
+func (r *OrderRepository) GetFullOrderList(ctx context.Context, userID string, cursorToken *string, page int64, limit int64) (*domain.Page[*domain.Order], error) {
+	args := pgx.NamedArgs{
 		"user_id": userID,
 		"status":  domain.CART,
-		"limit":   limit,
-		"offset":  (page - 1) * limit,
+		"limit":   limit + 1,
+		"offset":  int64(0),
+	}
+
+	where := "user_id=@user_id AND status<>@status"
+
+	var c *cursor
+	var err error
+
+	if cursorToken != nil {
+		c, err = decodeCursor(*cursorToken, "", orderSortColumns)
+		if err != nil {
+			return nil, err
+		}
+
+		where = fmt.Sprintf("%v AND %v", where, keysetWhere(orderSortColumns, c, args))
+	} else {
+		args["offset"] = (page - 1) * limit
 	}
 
-	rows, err := r.Conn.Query(ctx, query, args)
+	query := fmt.Sprintf(`
+		SELECT *, %v
+		FROM "order" 
+		WHERE %v
+		%v
+		LIMIT @limit OFFSET @offset
+	`, keysetSelect(orderSortColumns), where, keysetOrder(orderSortColumns, c != nil && c.Backward))
+
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	orders, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Order])
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[orderKeysetRow])
 	if err != nil {
 		return nil, err
 	}
 
-	return orders, nil
+	orders := make([]*domain.Order, len(result))
+	keys := make([][]any, len(result))
+
+	for i, row := range result {
+		orders[i] = &row.Order
+		keys[i] = row.CursorKey
+	}
+
+	return keysetPage(orders, keys, "", limit, c, args["offset"].(int64) > 0), nil
 }
 
 func (r *OrderRepository) GetCartInfo(ctx context.Context, userID string) (*domain.Order, error) {
@@ -169,12 +264,16 @@ func (r *OrderRepository) GetCartInfo(ctx context.Context, userID string) (*doma
 		"status":  domain.CART,
 	}
 
-	rows, err := r.Conn.Query(ctx, query, args)
+	rows, err := conn(ctx, r.Conn).Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
 	order, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +310,7 @@ func (r *OrderRepository) CreateCart(ctx context.Context, userID string) error {
 		"total":   0,
 	}
 
-	_, err = r.Conn.Exec(ctx, query, args)
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
@@ -219,23 +318,45 @@ func (r *OrderRepository) CreateCart(ctx context.Context, userID string) error {
 	return nil
 }
 
-func (r *OrderRepository) AddProductToCart(ctx context.Context, cart *domain.Order, gearID string) error {
+// parseCartLine parses the gear and the optional variant of a cart line
+func parseCartLine(gearID string, variantID *string) (uuid.UUID, *uuid.UUID, error) {
+	gearUUID, err := uuid.Parse(gearID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("%w: invalid gear uuid", domain.ErrNotFound)
+	}
+
+	if variantID == nil {
+		return gearUUID, nil, nil
+	}
+
+	variantUUID, err := uuid.Parse(*variantID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("%w: invalid variant uuid", domain.ErrNotFound)
+	}
+
+	return gearUUID, &variantUUID, nil
+}
+
+// AddProductToCart adds a line for the gear, or for one of its variants
+// when variantID is set
+func (r *OrderRepository) AddProductToCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string) error {
 	query := `
-		INSERT INTO gear_order (order_id, gear_id, quantity)
-		VALUES (@order_id, @gear_id, @quantity)
+		INSERT INTO gear_order (order_id, gear_id, variant_id, quantity)
+		VALUES (@order_id, @gear_id, @variant_id, @quantity)
 	`
 
-	gearUUID, err := uuid.Parse(gearID)
+	gearUUID, variantUUID, err := parseCartLine(gearID, variantID)
 	if err != nil {
-		return errors.New("invalid gear uuid")
+		return err
 	}
 
 	args := pgx.NamedArgs{
-		"order_id": cart.ID,
-		"gear_id":  gearUUID,
-		"quantity": 1,
+		"order_id":   cart.ID,
+		"gear_id":    gearUUID,
+		"variant_id": variantUUID,
+		"quantity":   1,
 	}
-	_, err = r.Conn.Exec(ctx, query, args)
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
@@ -243,52 +364,63 @@ func (r *OrderRepository) AddProductToCart(ctx context.Context, cart *domain.Ord
 	return nil
 }
 
-func (r *OrderRepository) SetGearQuantityCart(ctx context.Context, cart *domain.Order, gearID string, quantity int64) error {
+func (r *OrderRepository) SetGearQuantityCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string, quantity int64) error {
 	query := `
 		UPDATE gear_order
 		SET quantity=@quantity
 		WHERE order_id=@order_id AND gear_id=@gear_id
+			AND variant_id IS NOT DISTINCT FROM @variant_id::uuid
 	`
 
-	gearUUID, err := uuid.Parse(gearID)
+	gearUUID, variantUUID, err := parseCartLine(gearID, variantID)
 	if err != nil {
-		return errors.New("invalid gear uuid")
+		return err
 	}
 
 	args := pgx.NamedArgs{
-		"quantity": quantity,
-		"order_id": cart.ID,
-		"gear_id":  gearUUID,
+		"quantity":   quantity,
+		"order_id":   cart.ID,
+		"gear_id":    gearUUID,
+		"variant_id": variantUUID,
 	}
-	_, err = r.Conn.Exec(ctx, query, args)
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
 	return nil
 }
 
-func (r *OrderRepository) RemoveProductToCart(ctx context.Context, cart *domain.Order, gearID string) error {
+func (r *OrderRepository) RemoveProductToCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string) error {
 	query := `
 		DELETE FROM gear_order
 		WHERE order_id=@order_id AND gear_id=@gear_id
+			AND variant_id IS NOT DISTINCT FROM @variant_id::uuid
 	`
 
-	gearUUID, err := uuid.Parse(gearID)
+	gearUUID, variantUUID, err := parseCartLine(gearID, variantID)
 	if err != nil {
-		return errors.New("invalid gear uuid")
+		return err
 	}
 
 	args := pgx.NamedArgs{
-		"order_id": cart.ID,
-		"gear_id":  gearUUID,
-		"quantity": 1,
+		"order_id":   cart.ID,
+		"gear_id":    gearUUID,
+		"variant_id": variantUUID,
 	}
-	_, err = r.Conn.Exec(ctx, query, args)
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
 	return nil
 }
 
@@ -309,7 +441,7 @@ func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID string,
 		"status": status,
 	}
 
-	_, err = r.Conn.Exec(ctx, query, args)
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
@@ -334,7 +466,7 @@ func (r *OrderRepository) UpdateOrderTotalPrice(ctx context.Context, orderID str
 		"total": price,
 	}
 
-	_, err = r.Conn.Exec(ctx, query, args)
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
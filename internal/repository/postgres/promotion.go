@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const promotionColumns = `id, name, discount, priority, starts_at, ends_at, gear_ids, brands, categories, created_at`
+
+type PromotionRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPromotionRepository(conn *pgxpool.Pool) *PromotionRepository {
+	return &PromotionRepository{Conn: conn}
+}
+
+// promotionStatusWhere are the conditions of each domain.PromotionStatus
+var promotionStatusWhere = map[domain.PromotionStatus]string{
+	domain.PromotionScheduled: "WHERE now() < starts_at",
+	domain.PromotionRunning:   "WHERE starts_at <= now() AND now() < ends_at",
+	domain.PromotionEnded:     "WHERE ends_at <= now()",
+}
+
+// GetPromotionList returns the promotions of status, every promotion when
+// status is empty, the ones starting first first
+func (r *PromotionRepository) GetPromotionList(ctx context.Context, status domain.PromotionStatus) ([]*domain.Promotion, error) {
+	query := fmt.Sprintf(`
+		SELECT %v FROM promotion
+		%v
+		ORDER BY starts_at, id
+	`, promotionColumns, promotionStatusWhere[status])
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query)
+
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Promotion])
+}
+
+func (r *PromotionRepository) GetPromotionByID(ctx context.Context, id string) (*domain.Promotion, error) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %v FROM promotion WHERE id=@id
+	`, promotionColumns)
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	rows, _ := conn(ctx, r.Conn).Query(ctx, query, args)
+
+	promotion, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Promotion])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (r *PromotionRepository) AddPromotion(ctx context.Context, f *domain.AddPromotionForm) (uuid.UUID, error) {
+	query := `
+		INSERT INTO promotion (id, name, discount, priority, starts_at, ends_at, gear_ids, brands, categories)
+		VALUES (@id, @name, @discount, @priority, @starts_at, @ends_at, @gear_ids::uuid[], @brands::text[], @categories::text[])
+	`
+
+	newUUID, err := uuid.NewV7()
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	args := pgx.NamedArgs{
+		"id":         newUUID,
+		"name":       f.Name,
+		"discount":   f.Discount,
+		"priority":   f.Priority,
+		"starts_at":  f.StartsAt,
+		"ends_at":    f.EndsAt,
+		"gear_ids":   nonNil(f.GearIDs),
+		"brands":     nonNil(f.Brands),
+		"categories": nonNil(f.Categories),
+	}
+
+	_, err = conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return newUUID, nil
+}
+
+func (r *PromotionRepository) UpdatePromotion(ctx context.Context, id string, f *domain.UpdatePromotionForm) error {
+	b := newUpdateBuilder(
+		"promotion",
+		"name", "discount", "priority", "starts_at", "ends_at", "gear_ids", "brands", "categories",
+	)
+
+	for _, column := range []string{"gear_ids", "brands", "categories"} {
+		b.Transform(column, func(v any) (any, error) {
+			return nonNil(v.([]string)), nil
+		})
+	}
+
+	err := b.Form(f)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Exec(ctx, conn(ctx, r.Conn), id)
+}
+
+func (r *PromotionRepository) DeletePromotion(ctx context.Context, id string) error {
+	if err := uuid.Validate(id); err != nil {
+		return domain.ErrNotFound
+	}
+
+	query := `
+		DELETE FROM promotion
+		WHERE id=@id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := conn(ctx, r.Conn).Exec(ctx, query, args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// nonNil keeps an absent list from being sent as NULL to a NOT NULL column
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
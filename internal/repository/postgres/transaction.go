@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is implemented by both *pgxpool.Pool and pgx.Tx so repositories
+// can run the same query inside or outside of a transaction
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction stored in ctx by Transactor, or the pool
+// when the call is not part of a transaction
+func conn(ctx context.Context, pool *pgxpool.Pool) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return pool
+}
+
+type Transactor struct {
+	Conn *pgxpool.Pool
+}
+
+func NewTransactor(conn *pgxpool.Pool) *Transactor {
+	return &Transactor{Conn: conn}
+}
+
+// setGearChange hands the domain.GearChange of ctx to the triggers writing
+// gear_history, the settings last until the end of the transaction
+func setGearChange(ctx context.Context, tx pgx.Tx) error {
+	change := domain.GearChangeFrom(ctx)
+
+	if change == nil {
+		return nil
+	}
+
+	actorID := ""
+
+	if change.ActorID != nil {
+		actorID = change.ActorID.String()
+	}
+
+	query := `
+		SELECT
+			set_config('gear_manager.actor_id', @actor_id, true),
+			set_config('gear_manager.reason', @reason, true)
+	`
+
+	args := pgx.NamedArgs{
+		"actor_id": actorID,
+		"reason":   change.Reason,
+	}
+
+	_, err := tx.Exec(ctx, query, args)
+
+	return err
+}
+
+// WithinTransaction runs fn in a single transaction. Every repository call
+// made with the ctx given to fn joins that transaction. The transaction is
+// committed when fn returns nil and rolled back otherwise. Nested calls
+// reuse the outer transaction. The domain.GearChange of ctx, if any, is
+// recorded with the gear changes made by fn.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		err := setGearChange(ctx, tx)
+
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, t.Conn, func(tx pgx.Tx) error {
+		err := setGearChange(ctx, tx)
+
+		if err != nil {
+			return err
+		}
+
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
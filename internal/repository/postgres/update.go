@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// updateBuilder builds a parameterized UPDATE statement from a form struct.
+//
+// Every non nil pointer field with a `db` tag becomes a "column=@arg" pair,
+// values are always sent as arguments and never spliced into the SQL.
+// Fields tagged `db:"-"` are skipped, any other column must be in the
+// allowlist given to newUpdateBuilder.
+type updateBuilder struct {
+	table      string
+	columns    []string
+	transforms map[string]func(v any) (any, error)
+	sets       []string
+	args       pgx.NamedArgs
+}
+
+func newUpdateBuilder(table string, columns ...string) *updateBuilder {
+	return &updateBuilder{
+		table:      table,
+		columns:    columns,
+		transforms: map[string]func(v any) (any, error){},
+		sets:       []string{},
+		args:       pgx.NamedArgs{},
+	}
+}
+
+// Transform registers fn to convert the value of column before it is sent
+// to the database, e.g. mapping a gear type key to its stored value
+func (b *updateBuilder) Transform(column string, fn func(v any) (any, error)) *updateBuilder {
+	b.transforms[column] = fn
+	return b
+}
+
+// Form adds every non nil `db` tagged pointer field of form
+func (b *updateBuilder) Form(form any) error {
+	v := reflect.Indirect(reflect.ValueOf(form))
+
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("update form must be a struct, got %v", v.Kind())
+	}
+
+	t := v.Type()
+
+	for i := 0; i < v.NumField(); i++ {
+		column := t.Field(i).Tag.Get("db")
+		value := v.Field(i)
+
+		if column == "" || column == "-" {
+			continue
+		}
+
+		if value.Kind() != reflect.Pointer {
+			return fmt.Errorf("update field %v must be a pointer", t.Field(i).Name)
+		}
+
+		if value.IsNil() {
+			continue
+		}
+
+		err := b.Set(column, value.Elem().Interface())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Set adds a single column to the SET clause
+func (b *updateBuilder) Set(column string, value any) error {
+	if !slices.Contains(b.columns, column) {
+		return fmt.Errorf("column %v can't be updated", column)
+	}
+
+	if fn, ok := b.transforms[column]; ok {
+		v, err := fn(value)
+
+		if err != nil {
+			return err
+		}
+
+		value = v
+	}
+
+	arg := fmt.Sprintf("set_%v", column)
+	b.args[arg] = value
+	b.sets = append(b.sets, fmt.Sprintf("%v=@%v", pgx.Identifier{column}.Sanitize(), arg))
+
+	return nil
+}
+
+// Empty reports whether no column has been set yet
+func (b *updateBuilder) Empty() bool {
+	return len(b.sets) == 0
+}
+
+// Exec updates the row with the given id. It returns domain.ErrNotFound when
+// no row matched.
+func (b *updateBuilder) Exec(ctx context.Context, db DBTX, id string) error {
+	if b.Empty() {
+		return errors.New("field to update is required")
+	}
+
+	if err := uuid.Validate(id); err != nil {
+		return domain.ErrNotFound
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %v
+		SET %v
+		WHERE id=@id
+	`, pgx.Identifier{b.table}.Sanitize(), strings.Join(b.sets, ","))
+
+	b.args["id"] = id
+
+	tag, err := db.Exec(ctx, query, b.args)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -2,10 +2,6 @@ package postgres
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"reflect"
-	"strings"
 
 	"github.com/goldenfealla/gear-manager/domain"
 	"github.com/jackc/pgx/v5"
@@ -29,7 +25,7 @@ func (r *UserRepository) CheckIDExist(ctx context.Context, id string) (bool, err
 	}
 
 	var b bool
-	err := r.Conn.QueryRow(ctx, query, args).Scan(&b)
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&b)
 
 	if err != nil {
 		return false, err
@@ -47,7 +43,7 @@ func (r *UserRepository) CheckEmailExist(ctx context.Context, e string) (bool, e
 	}
 
 	var b bool
-	err := r.Conn.QueryRow(ctx, query, args).Scan(&b)
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&b)
 
 	if err != nil {
 		return false, err
@@ -65,7 +61,7 @@ func (r *UserRepository) CheckUsernameExist(ctx context.Context, un string) (boo
 	}
 
 	var b bool
-	err := r.Conn.QueryRow(ctx, query, args).Scan(&b)
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&b)
 
 	if err != nil {
 		return false, err
@@ -84,7 +80,7 @@ func (r *UserRepository) CheckUsernameOrEmailExist(ctx context.Context, unoe str
 	}
 
 	var b bool
-	err := r.Conn.QueryRow(ctx, query, args).Scan(&b)
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(&b)
 
 	if err != nil {
 		return false, err
@@ -95,14 +91,14 @@ func (r *UserRepository) CheckUsernameOrEmailExist(ctx context.Context, unoe str
 
 func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, first_name, last_name, phone, password, verified FROM "user" WHERE id=@id
+		SELECT id, username, email, first_name, last_name, phone, password, verified, role, session_version FROM "user" WHERE id=@id
 	`
 	args := &pgx.NamedArgs{
 		"id": id,
 	}
 
 	var user domain.User
-	err := r.Conn.QueryRow(ctx, query, args).Scan(
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -111,6 +107,8 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.Us
 		&user.Phone,
 		&user.Password,
 		&user.Verified,
+		&user.Role,
+		&user.SessionVersion,
 	)
 
 	if err != nil {
@@ -122,7 +120,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.Us
 
 func (r *UserRepository) GetByUsernameOrEmail(ctx context.Context, unoe string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, first_name, last_name, phone, password, verified FROM "user" WHERE (email=@email OR username=@username)
+		SELECT id, username, email, first_name, last_name, phone, password, verified, role, session_version FROM "user" WHERE (email=@email OR username=@username)
 	`
 	args := &pgx.NamedArgs{
 		"email":    unoe,
@@ -130,7 +128,7 @@ func (r *UserRepository) GetByUsernameOrEmail(ctx context.Context, unoe string)
 	}
 
 	var user domain.User
-	err := r.Conn.QueryRow(ctx, query, args).Scan(
+	err := conn(ctx, r.Conn).QueryRow(ctx, query, args).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -139,6 +137,8 @@ func (r *UserRepository) GetByUsernameOrEmail(ctx context.Context, unoe string)
 		&user.Phone,
 		&user.Password,
 		&user.Verified,
+		&user.Role,
+		&user.SessionVersion,
 	)
 
 	if err != nil {
@@ -159,7 +159,8 @@ func (r *UserRepository) AddUser(ctx context.Context, u *domain.User) error {
 				last_name, 
 				phone, 
 				password, 
-				verified
+				verified,
+				role
 			)
 		VALUES 
 			(
@@ -170,7 +171,8 @@ func (r *UserRepository) AddUser(ctx context.Context, u *domain.User) error {
 				@userLastName,
 				@userPhone,
 				@userPassword, 
-				@userVerified
+				@userVerified,
+				@userRole
 			)
 	`
 
@@ -183,9 +185,10 @@ func (r *UserRepository) AddUser(ctx context.Context, u *domain.User) error {
 		"userPhone":     u.Phone,
 		"userPassword":  u.Password,
 		"userVerified":  false,
+		"userRole":      u.Role,
 	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 
 	if err != nil {
 		return err
@@ -196,38 +199,30 @@ func (r *UserRepository) AddUser(ctx context.Context, u *domain.User) error {
 }
 
 func (r *UserRepository) UpdateUser(ctx context.Context, id string, u *domain.UpdateUserForm) error {
-	args := pgx.NamedArgs{
-		"id": id,
-	}
-
-	// iterate through struct to get field need to update
-	v := reflect.ValueOf(*u)
-	typeOfG := v.Type()
+	b := newUpdateBuilder("user", "username", "email", "first_name", "last_name", "phone")
 
-	fieldString := []string{}
+	err := b.Form(u)
 
-	for i := 0; i < v.NumField(); i++ {
-		field := typeOfG.Field(i).Tag.Get("db")
-		value := v.Field(i)
-
-		if !value.IsNil() {
-			fieldString = append(fieldString, fmt.Sprintf("%v='%v'", field, value.Elem()))
-		}
+	if err != nil {
+		return err
 	}
 
-	if len(fieldString) == 0 {
-		return errors.New("field to update is required")
-	}
+	return b.Exec(ctx, conn(ctx, r.Conn), id)
+}
 
-	query := fmt.Sprintf(`
-		UPDATE "user" 
-		SET %v 
-		WHERE id=@id;
-	`, strings.Join(fieldString, ","))
+func (r *UserRepository) UpdateUserRole(ctx context.Context, id string, role domain.Role) error {
+	query := `
+		UPDATE "user"
+		SET role=@role, session_version=session_version+1
+		WHERE id=@id
+	`
 
-	fmt.Println(query)
+	args := pgx.NamedArgs{
+		"id":   id,
+		"role": role,
+	}
 
-	_, err := r.Conn.Exec(ctx, query, args)
+	_, err := conn(ctx, r.Conn).Exec(ctx, query, args)
 
 	if err != nil {
 		return err
@@ -12,11 +12,11 @@ import (
 )
 
 type AddressUsecase interface {
-	GetAddressList(ctx context.Context, userID string) ([]*domain.Address, error)
-	GetAddressByID(ctx context.Context, id string) (*domain.Address, error)
+	GetAddressList(ctx context.Context, user *domain.UserInfo, userID string) ([]*domain.Address, error)
+	GetAddressByID(ctx context.Context, user *domain.UserInfo, id string) (*domain.Address, error)
 	AddAddress(ctx context.Context, userID string, g *domain.AddAddressForm) error
-	UpdateAddress(ctx context.Context, id string, g *domain.UpdateAddressForm) error
-	DeleteAddress(ctx context.Context, id string) error
+	UpdateAddress(ctx context.Context, user *domain.UserInfo, id string, g *domain.UpdateAddressForm) error
+	DeleteAddress(ctx context.Context, user *domain.UserInfo, id string) error
 }
 
 type AddressHandler struct {
@@ -49,6 +49,15 @@ func (h *AddressHandler) Test(c echo.Context) error {
 }
 
 func (h *AddressHandler) GetAddress(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
 	if hasID := c.QueryParams().Has("id"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'id' is required",
@@ -58,10 +67,10 @@ func (h *AddressHandler) GetAddress(c echo.Context) error {
 	id := c.QueryParams().Get("id")
 
 	ctx := c.Request().Context()
-	result, err := h.ac.GetAddressByID(ctx, id)
+	result, err := h.ac.GetAddressByID(ctx, u, id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -73,19 +82,27 @@ func (h *AddressHandler) GetAddress(c echo.Context) error {
 }
 
 func (h *AddressHandler) GetListAddress(c echo.Context) error {
-	if hasID := c.QueryParams().Has("user_id"); !hasID {
-		return c.JSON(http.StatusBadRequest, &domain.Response{
-			Message: "query param 'user_id' is required",
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
 		})
 	}
 
-	userID := c.QueryParams().Get("user_id")
+	// default to the addresses of the logged in user
+	userID := u.ID.String()
+
+	if hasID := c.QueryParams().Has("user_id"); hasID {
+		userID = c.QueryParams().Get("user_id")
+	}
 
 	ctx := c.Request().Context()
-	result, err := h.ac.GetAddressList(ctx, userID)
+	result, err := h.ac.GetAddressList(ctx, u, userID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -138,6 +155,15 @@ func (h *AddressHandler) AddAddress(c echo.Context) error {
 }
 
 func (h *AddressHandler) UpdateAddress(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
 	if hasID := c.QueryParams().Has("id"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'id' is required",
@@ -156,10 +182,10 @@ func (h *AddressHandler) UpdateAddress(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err = h.ac.UpdateAddress(ctx, id, &body)
+	err = h.ac.UpdateAddress(ctx, u, id, &body)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -170,6 +196,15 @@ func (h *AddressHandler) UpdateAddress(c echo.Context) error {
 }
 
 func (h *AddressHandler) DeleteAddress(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
 	if hasID := c.QueryParams().Has("id"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'id' is required",
@@ -179,10 +214,10 @@ func (h *AddressHandler) DeleteAddress(c echo.Context) error {
 	id := c.QueryParams().Get("id")
 
 	ctx := c.Request().Context()
-	err := h.ac.DeleteAddress(ctx, id)
+	err := h.ac.DeleteAddress(ctx, u, id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
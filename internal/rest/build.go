@@ -0,0 +1,507 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/leebenson/conform"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/internal/middleware"
+)
+
+type BuildUsecase interface {
+	GetBuild(ctx context.Context, user *domain.UserInfo, id string) (*domain.Build, error)
+	GetSharedBuild(ctx context.Context, token string) (*domain.Build, error)
+	GetBuildList(ctx context.Context, user *domain.UserInfo) ([]*domain.Build, error)
+	AddBuild(ctx context.Context, user *domain.UserInfo, f *domain.AddBuildForm) (*domain.Build, error)
+	CloneBuild(ctx context.Context, user *domain.UserInfo, id string, token string) (*domain.Build, error)
+	UpdateBuild(ctx context.Context, user *domain.UserInfo, id string, f *domain.UpdateBuildForm) error
+	ShareBuild(ctx context.Context, user *domain.UserInfo, id string) (string, error)
+	UnshareBuild(ctx context.Context, user *domain.UserInfo, id string) error
+	SetBuildSlot(ctx context.Context, user *domain.UserInfo, id string, f *domain.SetBuildSlotForm) error
+	RemoveBuildSlot(ctx context.Context, user *domain.UserInfo, id string, slot string) error
+	DeleteBuild(ctx context.Context, user *domain.UserInfo, id string) error
+	AddBuildToCart(ctx context.Context, user *domain.UserInfo, id string) error
+}
+
+type BuildHandler struct {
+	uc BuildUsecase
+	v  *validator.Validate
+}
+
+func NewBuildHandler(e *echo.Echo, uc BuildUsecase, v *validator.Validate) {
+	handler := &BuildHandler{
+		uc,
+		v,
+	}
+
+	group := e.Group("build")
+	group.Use(middleware.AuthenticatedWithConfig(&middleware.AuthenticatedConfig{
+		Excludes: []string{"/build/shared"},
+	}))
+
+	group.GET("", handler.GetBuild)
+	group.GET("/shared", handler.GetSharedBuild)
+	group.GET("/list", handler.GetBuildList)
+	group.POST("/create", handler.AddBuild)
+	group.POST("/clone", handler.CloneBuild)
+	group.PUT("/update", handler.UpdateBuild)
+	group.PUT("/share", handler.ShareBuild)
+	group.PUT("/unshare", handler.UnshareBuild)
+	group.PUT("/set-slot", handler.SetBuildSlot)
+	group.PUT("/remove-slot", handler.RemoveBuildSlot)
+	group.PUT("/add-to-cart", handler.AddBuildToCart)
+	group.DELETE("/delete", handler.DeleteBuild)
+}
+
+func (h *BuildHandler) GetBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetBuild(ctx, u, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) GetSharedBuild(c echo.Context) error {
+	if hasToken := c.QueryParams().Has("token"); !hasToken {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'token' is required",
+		})
+	}
+
+	token := c.QueryParams().Get("token")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetSharedBuild(ctx, token)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) GetBuildList(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetBuildList(ctx, u)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) AddBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	var body domain.AddBuildForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.AddBuild(ctx, u, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Created build",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) CloneBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+	token := c.QueryParams().Get("token")
+
+	if (id == "") == (token == "") {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "exactly one of query params 'id' and 'token' is required",
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.CloneBuild(ctx, u, id, token)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Cloned build",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) UpdateBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.UpdateBuildForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.UpdateBuild(ctx, u, id, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated build",
+	})
+}
+
+func (h *BuildHandler) ShareBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.ShareBuild(ctx, u, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Shared build",
+		Data:    result,
+	})
+}
+
+func (h *BuildHandler) UnshareBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.UnshareBuild(ctx, u, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Stopped sharing build",
+	})
+}
+
+func (h *BuildHandler) SetBuildSlot(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.SetBuildSlotForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.SetBuildSlot(ctx, u, id, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated build slot",
+	})
+}
+
+func (h *BuildHandler) RemoveBuildSlot(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasSlot := c.QueryParams().Has("slot"); !hasSlot {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'slot' is required",
+		})
+	}
+
+	slot := c.QueryParams().Get("slot")
+
+	ctx := c.Request().Context()
+	err := h.uc.RemoveBuildSlot(ctx, u, id, slot)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Removed build slot",
+	})
+}
+
+func (h *BuildHandler) AddBuildToCart(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.AddBuildToCart(ctx, u, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Added build to cart",
+	})
+}
+
+func (h *BuildHandler) DeleteBuild(c echo.Context) error {
+	u, ok := c.Get("user").(*domain.UserInfo)
+
+	if u == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.DeleteBuild(ctx, u, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Successfully delete build",
+	})
+}
@@ -0,0 +1,358 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/internal/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/leebenson/conform"
+)
+
+type CategoryUsecase interface {
+	GetCategoryTree(ctx context.Context) ([]*domain.Category, error)
+	GetCategoryByKey(ctx context.Context, key string) (*domain.Category, error)
+	AddCategory(ctx context.Context, f *domain.AddCategoryForm) error
+	UpdateCategory(ctx context.Context, key string, f *domain.UpdateCategoryForm) error
+	DeleteCategory(ctx context.Context, key string) error
+	GetCategoryAttributes(ctx context.Context, key string) ([]*domain.Attribute, error)
+	AddAttribute(ctx context.Context, key string, f *domain.AddAttributeForm) error
+	UpdateAttribute(ctx context.Context, key string, attributeKey string, f *domain.UpdateAttributeForm) error
+	DeleteAttribute(ctx context.Context, key string, attributeKey string) error
+}
+
+type CategoryHandler struct {
+	uc CategoryUsecase
+	v  *validator.Validate
+}
+
+func NewCategoryHandler(e *echo.Echo, uc CategoryUsecase, v *validator.Validate) {
+	handler := &CategoryHandler{
+		uc,
+		v,
+	}
+
+	group := e.Group("category")
+
+	manage := []echo.MiddlewareFunc{
+		middleware.AuthenticatedWithConfig(&middleware.AuthenticatedConfig{
+			Excludes: []string{},
+		}),
+		middleware.AuthorizedWithConfig(&middleware.AuthorizedConfig{
+			Permissions: []domain.Permission{domain.PermissionManageGear},
+		}),
+	}
+
+	group.GET("", handler.GetCategory)
+	group.GET("/list", handler.GetCategoryTree)
+	group.POST("/create", handler.AddCategory, manage...)
+	group.PUT("/update", handler.UpdateCategory, manage...)
+	group.DELETE("/delete", handler.DeleteCategory, manage...)
+	group.GET("/attribute/list", handler.GetCategoryAttributes)
+	group.POST("/attribute/create", handler.AddAttribute, manage...)
+	group.PUT("/attribute/update", handler.UpdateAttribute, manage...)
+	group.DELETE("/attribute/delete", handler.DeleteAttribute, manage...)
+}
+
+func (h *CategoryHandler) GetCategoryTree(c echo.Context) error {
+	ctx := c.Request().Context()
+	result, err := h.uc.GetCategoryTree(ctx)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *CategoryHandler) GetCategory(c echo.Context) error {
+	if hasKey := c.QueryParams().Has("key"); !hasKey {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'key' is required",
+		})
+	}
+
+	key := c.QueryParams().Get("key")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *CategoryHandler) AddCategory(c echo.Context) error {
+	var body domain.AddCategoryForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.AddCategory(ctx, &body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Created category",
+	})
+}
+
+func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
+	if hasKey := c.QueryParams().Has("key"); !hasKey {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'key' is required",
+		})
+	}
+
+	key := c.QueryParams().Get("key")
+
+	var body domain.UpdateCategoryForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.UpdateCategory(ctx, key, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated category",
+	})
+}
+
+func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
+	if hasKey := c.QueryParams().Has("key"); !hasKey {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'key' is required",
+		})
+	}
+
+	key := c.QueryParams().Get("key")
+
+	ctx := c.Request().Context()
+	err := h.uc.DeleteCategory(ctx, key)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Successfully delete category",
+	})
+}
+
+func (h *CategoryHandler) GetCategoryAttributes(c echo.Context) error {
+	if hasKey := c.QueryParams().Has("key"); !hasKey {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'key' is required",
+		})
+	}
+
+	key := c.QueryParams().Get("key")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetCategoryAttributes(ctx, key)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *CategoryHandler) AddAttribute(c echo.Context) error {
+	if hasKey := c.QueryParams().Has("key"); !hasKey {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'key' is required",
+		})
+	}
+
+	key := c.QueryParams().Get("key")
+
+	var body domain.AddAttributeForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.AddAttribute(ctx, key, &body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Created attribute",
+	})
+}
+
+func (h *CategoryHandler) UpdateAttribute(c echo.Context) error {
+	for _, param := range []string{"key", "attribute"} {
+		if !c.QueryParams().Has(param) {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: fmt.Sprintf("query param '%v' is required", param),
+			})
+		}
+	}
+
+	key := c.QueryParams().Get("key")
+	attributeKey := c.QueryParams().Get("attribute")
+
+	var body domain.UpdateAttributeForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.UpdateAttribute(ctx, key, attributeKey, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated attribute",
+	})
+}
+
+func (h *CategoryHandler) DeleteAttribute(c echo.Context) error {
+	for _, param := range []string{"key", "attribute"} {
+		if !c.QueryParams().Has(param) {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: fmt.Sprintf("query param '%v' is required", param),
+			})
+		}
+	}
+
+	key := c.QueryParams().Get("key")
+	attributeKey := c.QueryParams().Get("attribute")
+
+	ctx := c.Request().Context()
+	err := h.uc.DeleteAttribute(ctx, key, attributeKey)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Successfully delete attribute",
+	})
+}
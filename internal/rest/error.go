@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// errorStatus maps the domain errors returned by the usecases to an HTTP
+// status code, anything unknown is an internal error
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrVariantInUse), errors.Is(err, domain.ErrSKUTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrGearArchived), errors.Is(err, domain.ErrGearNotArchived), errors.Is(err, domain.ErrGearInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidImageOrder):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidVariant), errors.Is(err, domain.ErrVariantRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidCatalog), errors.Is(err, domain.ErrEmptyBulkRule), errors.Is(err, domain.ErrInvalidPromotion):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrBulkUndone), errors.Is(err, domain.ErrOrderNotCart):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrCartEmpty):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrImageDimensions):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, new(*domain.FilterError)):
+		return http.StatusBadRequest
+	case errors.As(err, new(*domain.SpecError)):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorResponse wraps err in a response, a filter or spec error is also sent
+// as data so the client can point at the offending param or attribute
+func errorResponse(err error) *domain.Response {
+	r := &domain.Response{
+		Message: err.Error(),
+	}
+
+	var fe *domain.FilterError
+
+	if errors.As(err, &fe) {
+		r.Data = fe
+	}
+
+	var se *domain.SpecError
+
+	if errors.As(err, &se) {
+		r.Data = se
+	}
+
+	return r
+}
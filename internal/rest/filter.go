@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// bindOptional binds the query param name into dest only when it is present,
+// so the defaults already in dest are kept otherwise
+func bindOptional[T any](c echo.Context, name string, dest **T, bind func(sourceParam string, dest *T) *echo.ValueBinder) {
+	if !c.QueryParams().Has(name) {
+		return
+	}
+
+	v := new(T)
+	bind(name, v)
+	*dest = v
+}
+
+// bindGearFilter binds the query params of the gear list endpoints. Unlike
+// c.Bind, a value that can't be parsed is reported as a *domain.FilterError
+// naming the offending param.
+func bindGearFilter(c echo.Context, filter *domain.ListGearFilter) error {
+	b := echo.QueryParamsBinder(c).FailFast(true)
+
+	bindOptional(c, "page", &filter.Page, b.Int64)
+	bindOptional(c, "limit", &filter.Limit, b.Int64)
+	bindOptional(c, "cursor", &filter.Cursor, b.String)
+	bindOptional(c, "with_count", &filter.WithCount, b.Bool)
+	bindOptional(c, "category", &filter.Category, b.String)
+	bindOptional(c, "q", &filter.Query, b.String)
+	bindOptional(c, "min_price", &filter.MinPrice, b.Float64)
+	bindOptional(c, "max_price", &filter.MaxPrice, b.Float64)
+	bindOptional(c, "min_discount", &filter.MinDiscount, b.Float64)
+	bindOptional(c, "max_discount", &filter.MaxDiscount, b.Float64)
+	bindOptional(c, "in_stock", &filter.InStock, b.Bool)
+	bindOptional(c, "price", &filter.Price, b.String)
+	bindOptional(c, "sort", &filter.Sort, b.String)
+	b.Strings("brand", &filter.Brand)
+	b.Strings("variety", &filter.Variety)
+
+	err := b.BindError()
+
+	if err == nil {
+		return bindSpecFilter(c, filter)
+	}
+
+	var be *echo.BindingError
+
+	if errors.As(err, &be) {
+		return &domain.FilterError{
+			Param:   be.Field,
+			Message: fmt.Sprintf("has invalid value '%v'", strings.Join(be.Values, ",")),
+		}
+	}
+
+	return err
+}
+
+// bindSpecFilter binds the "spec.<key>" params, repeated or comma separated
+// values are OR-ed, and the "spec.<key>.min" and "spec.<key>.max" bounds of
+// number attributes
+func bindSpecFilter(c echo.Context, filter *domain.ListGearFilter) error {
+	for name, values := range c.QueryParams() {
+		key, ok := strings.CutPrefix(name, "spec.")
+
+		if !ok {
+			continue
+		}
+
+		key, bound, _ := strings.Cut(key, ".")
+
+		if key == "" {
+			return &domain.FilterError{Param: name, Message: "must name an attribute"}
+		}
+
+		if filter.Specs == nil {
+			filter.Specs = map[string]*domain.SpecFilter{}
+		}
+
+		sf, ok := filter.Specs[key]
+
+		if !ok {
+			sf = &domain.SpecFilter{}
+			filter.Specs[key] = sf
+		}
+
+		switch bound {
+		case "":
+			for _, v := range values {
+				sf.Values = append(sf.Values, strings.Split(v, ",")...)
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(values[0], 64)
+
+			if err != nil {
+				return &domain.FilterError{Param: name, Message: fmt.Sprintf("has invalid value '%v'", values[0])}
+			}
+
+			if bound == "min" {
+				sf.Min = &n
+			} else {
+				sf.Max = &n
+			}
+		default:
+			return &domain.FilterError{Param: name, Message: "must end with '.min' or '.max'"}
+		}
+	}
+
+	return nil
+}
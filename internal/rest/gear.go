@@ -2,10 +2,15 @@ package rest
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/goldenfealla/gear-manager/domain"
+	image "github.com/goldenfealla/gear-manager/internal/file"
+	"github.com/goldenfealla/gear-manager/internal/middleware"
 	"github.com/labstack/echo/v4"
 	"github.com/leebenson/conform"
 )
@@ -14,11 +19,35 @@ type GearUsecase interface {
 	GetGearVarietyList(ctx context.Context, category string) ([]string, error)
 	GetGearBrandList(ctx context.Context, category string) ([]string, error)
 	GetGearListCount(ctx context.Context, filter domain.ListGearFilter) (int64, error)
-	GetGearList(ctx context.Context, filter domain.ListGearFilter) ([]*domain.Gear, error)
+	GetGearList(ctx context.Context, filter domain.ListGearFilter) (*domain.Page[*domain.Gear], error)
+	GetGearFacets(ctx context.Context, filter domain.ListGearFilter, buckets []float64) (*domain.GearFacets, error)
+	GetGearSuggestion(ctx context.Context, filter domain.SuggestGearFilter) ([]*domain.GearSuggestion, error)
 	GetGearByID(ctx context.Context, id string) (*domain.Gear, error)
+	CheckCompatibility(ctx context.Context, ids []string) (*domain.CompatibilityReport, error)
 	AddGear(ctx context.Context, g *domain.AddGearForm) error
 	UpdateGear(ctx context.Context, id string, g *domain.UpdateGearForm) error
 	DeleteGear(ctx context.Context, id string) error
+	RestoreGear(ctx context.Context, id string) error
+	PurgeGear(ctx context.Context, id string) error
+	GetGearImageList(ctx context.Context, gearID string) ([]*domain.GearImage, error)
+	AddGearImage(ctx context.Context, gearID string, img *domain.NewGearImage) (*domain.GearImage, error)
+	UpdateGearImage(ctx context.Context, gearID string, imageID string, f *domain.UpdateGearImageForm) error
+	SetPrimaryGearImage(ctx context.Context, gearID string, imageID string) error
+	ReorderGearImages(ctx context.Context, gearID string, imageIDs []string) error
+	DeleteGearImage(ctx context.Context, gearID string, imageID string) error
+	ReconcileImages(ctx context.Context, dryRun bool) (*domain.ImageReconcileReport, error)
+	GetGearVariantList(ctx context.Context, gearID string) ([]*domain.GearVariant, error)
+	SetVariantAxes(ctx context.Context, gearID string, f *domain.SetVariantAxesForm) error
+	AddGearVariant(ctx context.Context, gearID string, f *domain.AddGearVariantForm) (*domain.GearVariant, error)
+	UpdateGearVariant(ctx context.Context, gearID string, variantID string, f *domain.UpdateGearVariantForm) error
+	DeleteGearVariant(ctx context.Context, gearID string, variantID string) error
+	GetGearHistory(ctx context.Context, filter domain.ListGearHistoryFilter) (*domain.Page[*domain.GearHistory], error)
+	ImportGear(ctx context.Context, rows []*domain.GearImportRow, dryRun bool) (*domain.GearImportReport, error)
+	ExportGear(ctx context.Context, filter domain.ListGearFilter, fn func(f *domain.AddGearForm) error) error
+	PreviewBulkPrice(ctx context.Context, filter domain.ListGearFilter, rule *domain.BulkPriceRule) ([]*domain.BulkPriceChange, error)
+	ExecuteBulkPrice(ctx context.Context, filter domain.ListGearFilter, rule *domain.BulkPriceRule) (*domain.BulkPriceOperation, error)
+	GetBulkPriceOperation(ctx context.Context, id string) (*domain.BulkPriceOperation, error)
+	UndoBulkPrice(ctx context.Context, id string) (*domain.BulkPriceUndoReport, error)
 }
 
 type GearHandler struct {
@@ -34,15 +63,50 @@ func NewGearHandler(e *echo.Echo, uc GearUsecase, v *validator.Validate) {
 
 	group := e.Group("gear")
 
+	manage := []echo.MiddlewareFunc{
+		middleware.AuthenticatedWithConfig(&middleware.AuthenticatedConfig{
+			Excludes: []string{},
+		}),
+		middleware.AuthorizedWithConfig(&middleware.AuthorizedConfig{
+			Permissions: []domain.Permission{domain.PermissionManageGear},
+		}),
+	}
+
 	group.GET("/test", handler.Test)
 	group.GET("/", handler.GetGearByID)
 	group.GET("/list-count", handler.GetGearListCount)
 	group.GET("/list-brand", handler.GetGearBrandList)
 	group.GET("/list-variety", handler.GetGearVarietyList)
 	group.GET("/list", handler.GetGearList)
-	group.POST("/create", handler.AddGear)
-	group.PUT("/update", handler.UpdateGear)
-	group.DELETE("/delete", handler.DeleteGear)
+	group.GET("/facets", handler.GetGearFacets)
+	group.GET("/suggest", handler.GetGearSuggestion)
+	group.POST("/compatibility", handler.CheckCompatibility)
+	group.POST("/create", handler.AddGear, manage...)
+	group.PUT("/update", handler.UpdateGear, manage...)
+	group.DELETE("/delete", handler.DeleteGear, manage...)
+	group.GET("/archived-list", handler.GetArchivedGearList, manage...)
+	group.PUT("/restore", handler.RestoreGear, manage...)
+	group.DELETE("/purge", handler.PurgeGear, manage...)
+	group.GET("/image/list", handler.GetGearImageList)
+	group.POST("/image/add", handler.AddGearImage, manage...)
+	group.POST("/image/upload", handler.UploadGearImage, manage...)
+	group.PUT("/image/update", handler.UpdateGearImage, manage...)
+	group.PUT("/image/set-primary", handler.SetPrimaryGearImage, manage...)
+	group.PUT("/image/reorder", handler.ReorderGearImages, manage...)
+	group.DELETE("/image/delete", handler.DeleteGearImage, manage...)
+	group.POST("/image/reconcile", handler.ReconcileImages, manage...)
+	group.GET("/variant/list", handler.GetGearVariantList)
+	group.PUT("/variant/axes", handler.SetVariantAxes, manage...)
+	group.POST("/variant/create", handler.AddGearVariant, manage...)
+	group.PUT("/variant/update", handler.UpdateGearVariant, manage...)
+	group.DELETE("/variant/delete", handler.DeleteGearVariant, manage...)
+	group.GET("/history", handler.GetGearHistory, manage...)
+	group.POST("/import", handler.ImportGear, manage...)
+	group.GET("/export", handler.ExportGear, manage...)
+	group.GET("/bulk-price", handler.GetBulkPriceOperation, manage...)
+	group.POST("/bulk-price/preview", handler.PreviewBulkPrice, manage...)
+	group.POST("/bulk-price/execute", handler.ExecuteBulkPrice, manage...)
+	group.PUT("/bulk-price/undo", handler.UndoBulkPrice, manage...)
 }
 
 func (h *GearHandler) Test(c echo.Context) error {
@@ -108,21 +172,17 @@ func (h *GearHandler) GetGearListCount(c echo.Context) error {
 
 	filter := domain.ListGearFilter{}
 
-	err := c.Bind(&filter)
+	err := bindGearFilter(c, &filter)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	ctx := c.Request().Context()
 	result, err := h.uc.GetGearListCount(ctx, filter)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	return c.JSON(http.StatusOK, &domain.Response{
@@ -132,6 +192,16 @@ func (h *GearHandler) GetGearListCount(c echo.Context) error {
 }
 
 func (h *GearHandler) GetGearList(c echo.Context) error {
+	return h.listGear(c, false)
+}
+
+// GetArchivedGearList takes the same query params as GetGearList and lists
+// the archived gear only
+func (h *GearHandler) GetArchivedGearList(c echo.Context) error {
+	return h.listGear(c, true)
+}
+
+func (h *GearHandler) listGear(c echo.Context, archived bool) error {
 	if hasCategory := c.QueryParams().Has("category"); !hasCategory {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'category' is required",
@@ -142,15 +212,26 @@ func (h *GearHandler) GetGearList(c echo.Context) error {
 	defaultLimit := int64(10)
 
 	filter := domain.ListGearFilter{
-		Page:  &defaultPage,
-		Limit: &defaultLimit,
+		Page:     &defaultPage,
+		Limit:    &defaultLimit,
+		Archived: archived,
 	}
 
-	err := c.Bind(&filter)
+	err := bindGearFilter(c, &filter)
 
 	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	if *filter.Limit < 1 || *filter.Limit > 100 {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
-			Message: err.Error(),
+			Message: "query param 'limit' must be between 1 and 100",
+		})
+	}
+
+	if *filter.Page < 1 {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'page' must be greater than 0",
 		})
 	}
 
@@ -158,11 +239,117 @@ func (h *GearHandler) GetGearList(c echo.Context) error {
 	result, err := h.uc.GetGearList(ctx, filter)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+// GetGearFacets takes the same query params as GetGearList, plus an optional
+// "price_buckets" holding the comma separated lower bounds of each bucket
+func (h *GearHandler) GetGearFacets(c echo.Context) error {
+	if hasCategory := c.QueryParams().Has("category"); !hasCategory {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'category' is required",
+		})
+	}
+
+	filter := domain.ListGearFilter{}
+
+	err := bindGearFilter(c, &filter)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	buckets := domain.DefaultPriceBuckets
+
+	if c.QueryParams().Has("price_buckets") {
+		buckets = []float64{}
+
+		for _, v := range strings.Split(c.QueryParams().Get("price_buckets"), ",") {
+			b, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, &domain.Response{
+					Message: fmt.Sprintf("query param 'price_buckets' has invalid value '%v'", v),
+				})
+			}
+
+			buckets = append(buckets, b)
+		}
+
+		if !strictlyAscending(buckets) || len(buckets) == 0 || len(buckets) > 20 {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: "query param 'price_buckets' must be 1 to 20 strictly ascending values",
+			})
+		}
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetGearFacets(ctx, filter, buckets)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+// strictlyAscending reports whether every value is bigger than the one
+// before, a repeated bound would make an empty bucket
+func strictlyAscending(values []float64) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i] <= values[i-1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *GearHandler) GetGearSuggestion(c echo.Context) error {
+	defaultLimit := int64(5)
+
+	filter := domain.SuggestGearFilter{
+		Limit: &defaultLimit,
+	}
+
+	err := c.Bind(&filter)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: err.Error(),
 		})
 	}
 
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	if len([]rune(filter.Query)) < 2 {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'q' needs at least 2 characters",
+		})
+	}
+
+	if *filter.Limit < 1 || *filter.Limit > 20 {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'limit' must be between 1 and 20",
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetGearSuggestion(ctx, filter)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
 	return c.JSON(http.StatusOK, &domain.Response{
 		Message: "OK",
 		Data:    result,
@@ -182,7 +369,40 @@ func (h *GearHandler) GetGearByID(c echo.Context) error {
 	result, err := h.uc.GetGearByID(ctx, id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) CheckCompatibility(c echo.Context) error {
+	var body domain.CheckCompatibilityForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.CheckCompatibility(ctx, body.GearIDs)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -218,13 +438,11 @@ func (h *GearHandler) AddGear(c echo.Context) error {
 		})
 	}
 
-	ctx := c.Request().Context()
+	ctx := changeContext(c)
 	err = h.uc.AddGear(ctx, &body)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	return c.JSON(http.StatusCreated, &domain.Response{
@@ -250,13 +468,11 @@ func (h *GearHandler) UpdateGear(c echo.Context) error {
 		})
 	}
 
-	ctx := c.Request().Context()
+	ctx := changeContext(c)
 	err = h.uc.UpdateGear(ctx, id, &body)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	return c.JSON(http.StatusCreated, &domain.Response{
@@ -277,12 +493,374 @@ func (h *GearHandler) DeleteGear(c echo.Context) error {
 	err := h.uc.DeleteGear(ctx, id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Archived gear",
+	})
+}
+
+func (h *GearHandler) RestoreGear(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.RestoreGear(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Restored gear",
+	})
+}
+
+// PurgeGear deletes an archived gear for good, only when no order has it
+func (h *GearHandler) PurgeGear(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.PurgeGear(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Purged gear",
+	})
+}
+
+func (h *GearHandler) GetGearImageList(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetGearImageList(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) AddGearImage(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.AddGearImageForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	img, err := image.DecodeBase64Image(body.ImageBase64)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.AddGearImage(ctx, id, &domain.NewGearImage{
+		Image:   img,
+		AltText: body.AltText,
+		Primary: body.Primary,
+	})
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Added image",
+		Data:    result,
+	})
+}
+
+// UploadGearImage adds an image sent as multipart/form-data, prefer it over
+// AddGearImage which needs the whole image inlined as base64
+func (h *GearHandler) UploadGearImage(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	img, err := readImageUpload(c)
+
+	if err != nil {
+		status := errorStatus(err)
+
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+
+		return c.JSON(status, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.AddGearImage(ctx, id, img)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Uploaded image",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) UpdateGearImage(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasImageID := c.QueryParams().Has("image_id"); !hasImageID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'image_id' is required",
+		})
+	}
+
+	imageID := c.QueryParams().Get("image_id")
+
+	var body domain.UpdateGearImageForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.UpdateGearImage(ctx, id, imageID, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated image",
+	})
+}
+
+func (h *GearHandler) SetPrimaryGearImage(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasImageID := c.QueryParams().Has("image_id"); !hasImageID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'image_id' is required",
+		})
+	}
+
+	imageID := c.QueryParams().Get("image_id")
+
+	ctx := c.Request().Context()
+	err := h.uc.SetPrimaryGearImage(ctx, id, imageID)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
 
 	return c.JSON(http.StatusOK, &domain.Response{
-		Message: "Successfully delete document",
+		Message: "Updated primary image",
+	})
+}
+
+func (h *GearHandler) ReorderGearImages(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.ReorderGearImageForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.ReorderGearImages(ctx, id, body.ImageIDs)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Reordered images",
+	})
+}
+
+func (h *GearHandler) DeleteGearImage(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasImageID := c.QueryParams().Has("image_id"); !hasImageID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'image_id' is required",
+		})
+	}
+
+	imageID := c.QueryParams().Get("image_id")
+
+	ctx := c.Request().Context()
+	err := h.uc.DeleteGearImage(ctx, id, imageID)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Successfully delete image",
+	})
+}
+
+// ReconcileImages deletes the stored images no gear references. It only
+// reports them unless query param 'dry_run' is false.
+func (h *GearHandler) ReconcileImages(c echo.Context) error {
+	dryRun := true
+
+	if hasDryRun := c.QueryParams().Has("dry_run"); hasDryRun {
+		v, err := strconv.ParseBool(c.QueryParams().Get("dry_run"))
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: "query param 'dry_run' must be a boolean",
+			})
+		}
+
+		dryRun = v
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.ReconcileImages(ctx, dryRun)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Reconciled images",
+		Data:    result,
 	})
 }
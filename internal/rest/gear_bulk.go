@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// bindBulkPrice reads the rule from the body and the gear it applies to from
+// the filter params of GetGearList
+func (h *GearHandler) bindBulkPrice(c echo.Context) (domain.ListGearFilter, *domain.BulkPriceRule, error) {
+	var filter domain.ListGearFilter
+
+	err := bindGearFilter(c, &filter)
+
+	if err != nil {
+		return filter, nil, err
+	}
+
+	var rule domain.BulkPriceRule
+
+	err = c.Bind(&rule)
+
+	if err != nil {
+		return filter, nil, err
+	}
+
+	err = h.v.Struct(rule)
+
+	if err != nil {
+		return filter, nil, err
+	}
+
+	return filter, &rule, nil
+}
+
+func (h *GearHandler) PreviewBulkPrice(c echo.Context) error {
+	filter, rule, err := h.bindBulkPrice(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.PreviewBulkPrice(ctx, filter, rule)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) ExecuteBulkPrice(c echo.Context) error {
+	filter, rule, err := h.bindBulkPrice(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	ctx := changeContext(c)
+	result, err := h.uc.ExecuteBulkPrice(ctx, filter, rule)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Executed bulk operation",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) GetBulkPriceOperation(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetBulkPriceOperation(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) UndoBulkPrice(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := changeContext(c)
+	result, err := h.uc.UndoBulkPrice(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Undid bulk operation",
+		Data:    result,
+	})
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/internal/catalog"
+	"github.com/labstack/echo/v4"
+)
+
+// maxCatalogBytes caps the size of an imported catalog, rows may inline
+// their image as base64
+const maxCatalogBytes = 64 << 20
+
+// catalogFormat reads the 'format' query param, csv by default
+func catalogFormat(c echo.Context) (catalog.Format, error) {
+	if !c.QueryParams().Has("format") {
+		return catalog.FormatCSV, nil
+	}
+
+	return catalog.ParseFormat(c.QueryParams().Get("format"))
+}
+
+// ImportGear takes a CSV or JSON Lines catalog as the request body, see
+// package catalog for the columns. Every row is checked before anything is
+// written, with dry_run nothing is.
+func (h *GearHandler) ImportGear(c echo.Context) error {
+	format, err := catalogFormat(c)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	dryRun := false
+
+	if c.QueryParams().Has("dry_run") {
+		dryRun, err = strconv.ParseBool(c.QueryParams().Get("dry_run"))
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: "query param 'dry_run' must be a boolean",
+			})
+		}
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxCatalogBytes)
+
+	ctx := changeContext(c)
+	rows, err := catalog.ReadRows(ctx, body, format, h.v)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	report, err := h.uc.ImportGear(ctx, rows, dryRun)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	if len(report.Errors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, &domain.Response{
+			Message: fmt.Sprintf("%v rows have errors, nothing was imported", len(report.Errors)),
+			Data:    report,
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    report,
+	})
+}
+
+// ExportGear streams the gear matching the filter params of GetGearList in
+// the format ImportGear reads. The category defaults to every gear.
+func (h *GearHandler) ExportGear(c echo.Context) error {
+	format, err := catalogFormat(c)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	var filter domain.ListGearFilter
+
+	err = bindGearFilter(c, &filter)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=gear.%v", format))
+
+	w := catalog.NewWriter(res, format)
+	rows := 0
+
+	ctx := c.Request().Context()
+	err = h.uc.ExportGear(ctx, filter, func(f *domain.AddGearForm) error {
+		err := w.Write(f)
+
+		if err != nil {
+			return err
+		}
+
+		// the status is sent with the first page, an error past it can
+		// only cut the file short
+		if rows++; rows%100 == 0 {
+			err = w.Flush()
+			res.Flush()
+		}
+
+		return err
+	})
+
+	if err != nil && !res.Committed {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
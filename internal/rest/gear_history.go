@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// changeContext returns the request context carrying the logged in user as
+// the actor of the gear changes, with the optional 'reason' query param
+func changeContext(c echo.Context) context.Context {
+	change := &domain.GearChange{
+		Reason: c.QueryParams().Get("reason"),
+	}
+
+	if user, ok := c.Get("user").(*domain.UserInfo); ok && user != nil {
+		change.ActorID = &user.ID
+	}
+
+	return domain.WithGearChange(c.Request().Context(), change)
+}
+
+func (h *GearHandler) GetGearHistory(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	filter := domain.ListGearHistoryFilter{
+		GearID: c.QueryParams().Get("id"),
+		Page:   1,
+		Limit:  10,
+	}
+
+	if c.QueryParams().Has("variant_id") {
+		variantID := c.QueryParams().Get("variant_id")
+		filter.VariantID = &variantID
+	}
+
+	pPage := c.QueryParams().Get("page")
+	pLimit := c.QueryParams().Get("limit")
+
+	var err error
+
+	if pPage != "" {
+		filter.Page, err = strconv.ParseInt(pPage, 10, 64)
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: err.Error(),
+			})
+		}
+	}
+
+	if pLimit != "" {
+		filter.Limit, err = strconv.ParseInt(pLimit, 10, 64)
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &domain.Response{
+				Message: err.Error(),
+			})
+		}
+	}
+
+	if filter.Page < 1 || filter.Limit < 1 || filter.Limit > 100 {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'page' must be greater than 0 and 'limit' between 1 and 100",
+		})
+	}
+
+	if c.QueryParams().Has("cursor") {
+		token := c.QueryParams().Get("cursor")
+		filter.Cursor = &token
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetGearHistory(ctx, filter)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
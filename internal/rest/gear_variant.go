@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/labstack/echo/v4"
+	"github.com/leebenson/conform"
+)
+
+func (h *GearHandler) GetGearVariantList(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetGearVariantList(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) SetVariantAxes(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.SetVariantAxesForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.SetVariantAxes(ctx, id, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated variant axes",
+	})
+}
+
+func (h *GearHandler) AddGearVariant(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.AddGearVariantForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := changeContext(c)
+	result, err := h.uc.AddGearVariant(ctx, id, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Added variant",
+		Data:    result,
+	})
+}
+
+func (h *GearHandler) UpdateGearVariant(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasVariantID := c.QueryParams().Has("variant_id"); !hasVariantID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'variant_id' is required",
+		})
+	}
+
+	variantID := c.QueryParams().Get("variant_id")
+
+	var body domain.UpdateGearVariantForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := changeContext(c)
+	err = h.uc.UpdateGearVariant(ctx, id, variantID, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated variant",
+	})
+}
+
+func (h *GearHandler) DeleteGearVariant(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	if hasVariantID := c.QueryParams().Has("variant_id"); !hasVariantID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'variant_id' is required",
+		})
+	}
+
+	variantID := c.QueryParams().Get("variant_id")
+
+	ctx := changeContext(c)
+	err := h.uc.DeleteGearVariant(ctx, id, variantID)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Deleted variant",
+	})
+}
@@ -14,12 +14,12 @@ import (
 
 type OrderUsecase interface {
 	GetCart(ctx context.Context, userID string) (*domain.FullOrder, error)
-	AddGearToCart(ctx context.Context, userID string, gearID string) error
-	SetGearQuantityCart(ctx context.Context, orderID string, gearID string, quantity int64) error
-	RemoveGearFromCart(ctx context.Context, userID string, gearID string) error
-	PayCart(ctx context.Context, orderID string) error
-	GetOrder(ctx context.Context, d string) (*domain.FullOrder, error)
-	GetOrderList(ctx context.Context, orderID string, page int64, limit int64) ([]*domain.Order, error)
+	AddGearToCart(ctx context.Context, userID string, gearID string, variantID string) error
+	SetGearQuantityCart(ctx context.Context, orderID string, gearID string, variantID string, quantity int64) error
+	RemoveGearFromCart(ctx context.Context, userID string, gearID string, variantID string) error
+	PayCart(ctx context.Context, user *domain.UserInfo, orderID string) error
+	GetOrder(ctx context.Context, user *domain.UserInfo, id string) (*domain.FullOrder, error)
+	GetOrderList(ctx context.Context, userID string, cursor *string, page int64, limit int64) (*domain.Page[*domain.Order], error)
 }
 
 type OrderHandler struct {
@@ -95,12 +95,14 @@ func (h *OrderHandler) AddGearToCart(c echo.Context) error {
 	}
 
 	gearID := c.QueryParam("gear_id")
+	// variant_id is required for a gear with variants
+	variantID := c.QueryParam("variant_id")
 
 	ctx := c.Request().Context()
-	err := h.ou.AddGearToCart(ctx, user.ID.String(), gearID)
+	err := h.ou.AddGearToCart(ctx, user.ID.String(), gearID, variantID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -128,6 +130,7 @@ func (h *OrderHandler) SetGearQuantityCart(c echo.Context) error {
 	}
 
 	gearID := c.QueryParam("gear_id")
+	variantID := c.QueryParam("variant_id")
 
 	if hasID := c.QueryParams().Has("quantity"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
@@ -143,11 +146,9 @@ func (h *OrderHandler) SetGearQuantityCart(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err = h.ou.SetGearQuantityCart(ctx, user.ID.String(), gearID, quantity)
+	err = h.ou.SetGearQuantityCart(ctx, user.ID.String(), gearID, variantID, quantity)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	return c.JSON(http.StatusOK, &domain.Response{
@@ -173,14 +174,13 @@ func (h *OrderHandler) RemoveGearFromCart(c echo.Context) error {
 	}
 
 	gearID := c.QueryParam("gear_id")
+	variantID := c.QueryParam("variant_id")
 
 	ctx := c.Request().Context()
-	err := h.ou.RemoveGearFromCart(ctx, user.ID.String(), gearID)
+	err := h.ou.RemoveGearFromCart(ctx, user.ID.String(), gearID, variantID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
-			Message: err.Error(),
-		})
+		return c.JSON(errorStatus(err), errorResponse(err))
 	}
 
 	return c.JSON(http.StatusOK, &domain.Response{
@@ -190,6 +190,15 @@ func (h *OrderHandler) RemoveGearFromCart(c echo.Context) error {
 }
 
 func (h *OrderHandler) PayCart(c echo.Context) error {
+	user, ok := c.Get("user").(*domain.UserInfo)
+
+	if user == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
 	if hasID := c.QueryParams().Has("id"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'id' is required",
@@ -199,9 +208,9 @@ func (h *OrderHandler) PayCart(c echo.Context) error {
 	orderID := c.QueryParam("id")
 
 	ctx := c.Request().Context()
-	err := h.ou.PayCart(ctx, orderID)
+	err := h.ou.PayCart(ctx, user, orderID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -249,11 +258,24 @@ func (h *OrderHandler) GetOrderList(c echo.Context) error {
 		}
 	}
 
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'page' must be greater than 0 and 'limit' between 1 and 100",
+		})
+	}
+
+	var cursor *string
+
+	if c.QueryParams().Has("cursor") {
+		token := c.QueryParams().Get("cursor")
+		cursor = &token
+	}
+
 	ctx := c.Request().Context()
-	result, err := h.ou.GetOrderList(ctx, user.ID.String(), page, limit)
+	result, err := h.ou.GetOrderList(ctx, user.ID.String(), cursor, page, limit)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
@@ -265,6 +287,15 @@ func (h *OrderHandler) GetOrderList(c echo.Context) error {
 }
 
 func (h *OrderHandler) GetOrder(c echo.Context) error {
+	user, ok := c.Get("user").(*domain.UserInfo)
+
+	if user == nil || !ok {
+		return c.JSON(http.StatusUnauthorized, &domain.Response{
+			Message: "You need to login",
+			Data:    nil,
+		})
+	}
+
 	if hasID := c.QueryParams().Has("id"); !hasID {
 		return c.JSON(http.StatusBadRequest, &domain.Response{
 			Message: "query param 'id' is required",
@@ -274,10 +305,10 @@ func (h *OrderHandler) GetOrder(c echo.Context) error {
 	id := c.QueryParams().Get("id")
 
 	ctx := c.Request().Context()
-	result, err := h.ou.GetOrder(ctx, id)
+	result, err := h.ou.GetOrder(ctx, user, id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &domain.Response{
+		return c.JSON(errorStatus(err), &domain.Response{
 			Message: err.Error(),
 		})
 	}
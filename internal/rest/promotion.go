@@ -0,0 +1,195 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/internal/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/leebenson/conform"
+)
+
+type PromotionUsecase interface {
+	GetPromotionList(ctx context.Context, status domain.PromotionStatus) ([]*domain.Promotion, error)
+	GetPromotionByID(ctx context.Context, id string) (*domain.Promotion, error)
+	AddPromotion(ctx context.Context, f *domain.AddPromotionForm) (*domain.Promotion, error)
+	UpdatePromotion(ctx context.Context, id string, f *domain.UpdatePromotionForm) error
+	DeletePromotion(ctx context.Context, id string) error
+}
+
+type PromotionHandler struct {
+	uc PromotionUsecase
+	v  *validator.Validate
+}
+
+func NewPromotionHandler(e *echo.Echo, uc PromotionUsecase, v *validator.Validate) {
+	handler := &PromotionHandler{
+		uc,
+		v,
+	}
+
+	group := e.Group("promotion")
+
+	manage := []echo.MiddlewareFunc{
+		middleware.AuthenticatedWithConfig(&middleware.AuthenticatedConfig{
+			Excludes: []string{},
+		}),
+		middleware.AuthorizedWithConfig(&middleware.AuthorizedConfig{
+			Permissions: []domain.Permission{domain.PermissionManageGear},
+		}),
+	}
+
+	group.GET("", handler.GetPromotion)
+	group.GET("/list", handler.GetPromotionList)
+	group.POST("/create", handler.AddPromotion, manage...)
+	group.PUT("/update", handler.UpdatePromotion, manage...)
+	group.DELETE("/delete", handler.DeletePromotion, manage...)
+}
+
+// GetPromotionList lists every promotion, or the ones of the 'status' query
+// param: scheduled, running or ended
+func (h *PromotionHandler) GetPromotionList(c echo.Context) error {
+	status := domain.PromotionStatus(c.QueryParams().Get("status"))
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetPromotionList(ctx, status)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *PromotionHandler) GetPromotion(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	result, err := h.uc.GetPromotionByID(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "OK",
+		Data:    result,
+	})
+}
+
+func (h *PromotionHandler) AddPromotion(c echo.Context) error {
+	var body domain.AddPromotionForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.uc.AddPromotion(ctx, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusCreated, &domain.Response{
+		Message: "Created promotion",
+		Data:    result,
+	})
+}
+
+func (h *PromotionHandler) UpdatePromotion(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	var body domain.UpdatePromotionForm
+	err := c.Bind(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = conform.Strings(&body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	err = h.v.Struct(body)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	err = h.uc.UpdatePromotion(ctx, id, &body)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated promotion",
+	})
+}
+
+func (h *PromotionHandler) DeletePromotion(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+
+	ctx := c.Request().Context()
+	err := h.uc.DeletePromotion(ctx, id)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Deleted promotion",
+	})
+}
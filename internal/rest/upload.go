@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	image "github.com/goldenfealla/gear-manager/internal/file"
+	"github.com/labstack/echo/v4"
+)
+
+// maxUploadOverhead leaves room for the text fields and the multipart
+// headers on top of the image itself
+const maxUploadOverhead = 64 << 10
+
+const maxAltTextLength = 256
+
+// readImageUpload streams a multipart/form-data request with an "image" file
+// and optional "alt_text" and "primary" fields. The parts are read as they
+// arrive and the image is decoded as it streams from the request body, only
+// its header is held in memory before the pixels are decoded.
+func readImageUpload(c echo.Context) (*domain.NewGearImage, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, image.MaxImageBytes+maxUploadOverhead)
+
+	reader, err := req.MultipartReader()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.NewGearImage{}
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, uploadError(err)
+		}
+
+		switch part.FormName() {
+		case "image":
+			if result.Image != nil {
+				return nil, errors.New("form field 'image' must only be sent once")
+			}
+
+			result.Image, err = image.DecodeImage(part)
+		case "alt_text":
+			var v []byte
+			v, err = io.ReadAll(io.LimitReader(part, maxAltTextLength+1))
+			result.AltText = strings.TrimSpace(string(v))
+
+			if err == nil && len(result.AltText) > maxAltTextLength {
+				err = fmt.Errorf("form field 'alt_text' must be at most %v bytes", maxAltTextLength)
+			}
+		case "primary":
+			var v []byte
+			v, err = io.ReadAll(io.LimitReader(part, 8))
+
+			if err == nil {
+				result.Primary, err = strconv.ParseBool(strings.TrimSpace(string(v)))
+			}
+		}
+
+		part.Close()
+
+		if err != nil {
+			return nil, uploadError(err)
+		}
+	}
+
+	if result.Image == nil {
+		return nil, errors.New("form field 'image' is required")
+	}
+
+	return result, nil
+}
+
+// uploadError reports a body cut by the size limit as too large
+func uploadError(err error) error {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		return domain.ErrImageTooLarge
+	}
+
+	return err
+}
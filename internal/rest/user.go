@@ -3,6 +3,7 @@ package rest
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/goldenfealla/gear-manager/domain"
@@ -17,6 +18,7 @@ type UserUsecase interface {
 	RegisterUser(ctx context.Context, f *domain.RegisterUserForm) (*domain.UserInfo, error)
 	LoginUser(ctx context.Context, f *domain.LoginUserForm) (*domain.UserInfo, error)
 	UpdateUser(ctx context.Context, id string, f *domain.UpdateUserForm) (*domain.UserInfo, error)
+	SetUserRole(ctx context.Context, id string, role domain.Role) (*domain.UserInfo, error)
 }
 
 type UserHandler struct {
@@ -46,6 +48,9 @@ func NewUserHandler(e *echo.Echo, uc UserUsecase, v *validator.Validate) {
 
 	group.POST("/register", handler.Register)
 	group.PUT("/update", handler.Update)
+	group.PUT("/set-role", handler.SetRole, middleware.AuthorizedWithConfig(&middleware.AuthorizedConfig{
+		Permissions: []domain.Permission{domain.PermissionManageUser},
+	}))
 
 	group.POST("/login", handler.Login)
 	group.GET("/logout", handler.Logout)
@@ -71,6 +76,8 @@ func (h *UserHandler) Check(c echo.Context) error {
 	})
 }
 
+// Refresh issues a new token from the user as read from the database by
+// middleware.SessionUser, never from the claims of the old token
 func (h *UserHandler) Refresh(c echo.Context) error {
 	user := c.Get("user").(*domain.UserInfo)
 
@@ -261,6 +268,35 @@ func (h *UserHandler) Update(c echo.Context) error {
 	})
 }
 
+func (h *UserHandler) SetRole(c echo.Context) error {
+	if hasID := c.QueryParams().Has("id"); !hasID {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'id' is required",
+		})
+	}
+
+	if hasRole := c.QueryParams().Has("role"); !hasRole {
+		return c.JSON(http.StatusBadRequest, &domain.Response{
+			Message: "query param 'role' is required",
+		})
+	}
+
+	id := c.QueryParams().Get("id")
+	role := domain.Role(strings.ToUpper(c.QueryParams().Get("role")))
+
+	ctx := c.Request().Context()
+	info, err := h.uc.SetUserRole(ctx, id, role)
+
+	if err != nil {
+		return c.JSON(errorStatus(err), errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, &domain.Response{
+		Message: "Updated user role",
+		Data:    info,
+	})
+}
+
 func (h *UserHandler) Logout(c echo.Context) error {
 	err := session.DeleteSession(c)
 
@@ -37,6 +37,16 @@ func IsAuth(c echo.Context) (*domain.UserInfo, error) {
 		return nil, err
 	}
 
+	// sessions issued before roles existed carry no role claim
+	role, ok := claims["role"].(string)
+
+	if !ok || role == "" {
+		role = string(domain.CUSTOMER)
+	}
+
+	// sessions issued before session versions existed are of version 0
+	version, _ := claims["session_version"].(float64)
+
 	ui := &domain.UserInfo{
 		ID:        uid,
 		Username:  claims["username"].(string),
@@ -44,6 +54,9 @@ func IsAuth(c echo.Context) (*domain.UserInfo, error) {
 		FirstName: claims["first_name"].(string),
 		LastName:  claims["last_name"].(string),
 		Phone:     claims["phone"].(string),
+		Role:      domain.Role(role),
+
+		SessionVersion: int64(version),
 	}
 
 	return ui, nil
@@ -1,17 +1,18 @@
 package validation
 
 import (
-	"strings"
+	"context"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/goldenfealla/gear-manager/domain"
 )
 
-func ValidateIsGear(fl validator.FieldLevel) bool {
-	val := fl.Field().String()
-	key := strings.ToLower(val)
-	if _, ok := domain.GearTypeMap[key]; ok {
-		return true
+type CategoryLookup interface {
+	HasCategory(ctx context.Context, key string) bool
+}
+
+// ValidateIsGear checks the field is the key of a stored category
+func ValidateIsGear(categories CategoryLookup) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		return categories.HasCategory(ctx, fl.Field().String())
 	}
-	return false
 }
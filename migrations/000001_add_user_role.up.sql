@@ -0,0 +1,3 @@
+ALTER TABLE "user"
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'CUSTOMER'
+    CHECK (role IN ('CUSTOMER', 'STAFF', 'ADMIN'));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS gear_search_vector_idx;
+
+ALTER TABLE gear DROP COLUMN search_vector;
@@ -0,0 +1,9 @@
+-- gear.description will be added to the document with weight 'D' once gear has one
+ALTER TABLE gear
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(brand, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(variety, '')), 'C')
+    ) STORED;
+
+CREATE INDEX gear_search_vector_idx ON gear USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS gear_brand_trgm_idx;
+DROP INDEX IF EXISTS gear_name_trgm_idx;
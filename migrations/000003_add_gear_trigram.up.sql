@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX gear_name_trgm_idx ON gear USING GIN (name gin_trgm_ops);
+CREATE INDEX gear_brand_trgm_idx ON gear USING GIN (brand gin_trgm_ops);
@@ -0,0 +1,3 @@
+ALTER TABLE gear DROP CONSTRAINT IF EXISTS gear_type_fkey;
+
+DROP TABLE IF EXISTS category;
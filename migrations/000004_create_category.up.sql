@@ -0,0 +1,33 @@
+CREATE TABLE category (
+    id UUID PRIMARY KEY,
+    key VARCHAR(32) NOT NULL UNIQUE,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(64) NOT NULL,
+    parent_id UUID REFERENCES category (id),
+    position BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX category_parent_id_idx ON category (parent_id);
+
+INSERT INTO category (id, key, code, name, position) VALUES
+    (gen_random_uuid(), 'pc', 'PERSONAL_COMPUTER', 'PC', 0),
+    (gen_random_uuid(), 'laptop', 'LAPTOP', 'Laptop', 1),
+    (gen_random_uuid(), 'components', 'COMPONENTS', 'Components', 2),
+    (gen_random_uuid(), 'monitor', 'MONITOR', 'Monitor', 3);
+
+INSERT INTO category (id, key, code, name, parent_id, position)
+SELECT gen_random_uuid(), v.key, v.code, v.name, c.id, v.position
+FROM (VALUES
+    ('mainboard', 'MAINBOARD', 'Mainboard', 0),
+    ('cpu', 'CENTRAL_PROCESSING_UNIT', 'CPU', 1),
+    ('gpu', 'GRAPHICS_PROCESSING_UNIT', 'GPU', 2),
+    ('ram', 'RANDOM_ACCESS_MEMORY', 'RAM', 3),
+    ('storage', 'STORAGE', 'Storage', 4),
+    ('psu', 'POWER_SUPPLY_UNIT', 'PSU', 5),
+    ('fan', 'FAN', 'Fan', 6)
+) AS v (key, code, name, position)
+CROSS JOIN category c
+WHERE c.key = 'components';
+
+ALTER TABLE gear
+    ADD CONSTRAINT gear_type_fkey FOREIGN KEY (type) REFERENCES category (code) ON UPDATE CASCADE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS gear_specs_idx;
+
+ALTER TABLE gear DROP COLUMN IF EXISTS specs;
+
+DROP TABLE IF EXISTS category_attribute;
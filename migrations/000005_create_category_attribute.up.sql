@@ -0,0 +1,34 @@
+CREATE TABLE category_attribute (
+    id UUID PRIMARY KEY,
+    category_id UUID NOT NULL REFERENCES category (id) ON DELETE CASCADE,
+    key VARCHAR(32) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    unit VARCHAR(16),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    position BIGINT NOT NULL DEFAULT 0,
+    UNIQUE (category_id, key)
+);
+
+ALTER TABLE gear ADD COLUMN specs JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX gear_specs_idx ON gear USING GIN (specs);
+
+INSERT INTO category_attribute (id, category_id, key, name, type, unit, options, position)
+SELECT gen_random_uuid(), c.id, v.key, v.name, v.type, v.unit, v.options, v.position
+FROM (VALUES
+    ('cpu', 'socket', 'Socket', 'enum', NULL, ARRAY['AM4', 'AM5', 'LGA1700', 'LGA1851'], 0),
+    ('cpu', 'tdp', 'TDP', 'number', 'W', '{}'::text[], 1),
+    ('mainboard', 'socket', 'Socket', 'enum', NULL, ARRAY['AM4', 'AM5', 'LGA1700', 'LGA1851'], 0),
+    ('mainboard', 'memory_type', 'Memory type', 'enum', NULL, ARRAY['DDR4', 'DDR5'], 1),
+    ('mainboard', 'form_factor', 'Form factor', 'enum', NULL, ARRAY['ATX', 'Micro-ATX', 'Mini-ITX'], 2),
+    ('ram', 'memory_type', 'Memory type', 'enum', NULL, ARRAY['DDR4', 'DDR5'], 0),
+    ('ram', 'capacity', 'Capacity', 'number', 'GB', '{}'::text[], 1),
+    ('gpu', 'tdp', 'TDP', 'number', 'W', '{}'::text[], 0),
+    ('gpu', 'length', 'Length', 'number', 'mm', '{}'::text[], 1),
+    ('psu', 'wattage', 'Wattage', 'number', 'W', '{}'::text[], 0),
+    ('monitor', 'refresh_rate', 'Refresh rate', 'number', 'Hz', '{}'::text[], 0),
+    ('monitor', 'panel', 'Panel', 'enum', NULL, ARRAY['IPS', 'VA', 'TN', 'OLED'], 1)
+) AS v (category, key, name, type, unit, options, position)
+JOIN category c ON c.key = v.category;
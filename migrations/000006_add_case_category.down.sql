@@ -0,0 +1 @@
+DELETE FROM category WHERE key = 'case';
@@ -0,0 +1,9 @@
+INSERT INTO category (id, key, code, name, parent_id, position)
+SELECT gen_random_uuid(), 'case', 'CASE', 'Case', c.id, 7
+FROM category c
+WHERE c.key = 'components';
+
+INSERT INTO category_attribute (id, category_id, key, name, type, unit, options, position)
+SELECT gen_random_uuid(), c.id, 'max_gpu_length', 'Max GPU length', 'number', 'mm', '{}', 0
+FROM category c
+WHERE c.key = 'case';
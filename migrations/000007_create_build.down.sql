@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS build_slot;
+
+DROP TABLE IF EXISTS build;
@@ -0,0 +1,16 @@
+CREATE TABLE build (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    share_token VARCHAR(32) UNIQUE
+);
+
+CREATE INDEX build_user_id_idx ON build (user_id);
+
+CREATE TABLE build_slot (
+    build_id UUID NOT NULL REFERENCES build (id) ON DELETE CASCADE,
+    slot VARCHAR(32) NOT NULL,
+    gear_id UUID NOT NULL REFERENCES gear (id) ON DELETE CASCADE,
+    quantity BIGINT NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    PRIMARY KEY (build_id, slot)
+);
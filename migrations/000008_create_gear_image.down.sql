@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gear_image;
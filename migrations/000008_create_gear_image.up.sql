@@ -0,0 +1,16 @@
+CREATE TABLE gear_image (
+    id UUID PRIMARY KEY,
+    gear_id UUID NOT NULL REFERENCES gear (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    alt_text VARCHAR(256) NOT NULL DEFAULT '',
+    position BIGINT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX gear_image_gear_id_idx ON gear_image (gear_id, position);
+
+-- the single image every gear had so far becomes its primary image
+INSERT INTO gear_image (id, gear_id, url, alt_text, position, is_primary)
+SELECT gen_random_uuid(), id, image_url, name, 0, true
+FROM gear
+WHERE image_url <> '';
@@ -0,0 +1,3 @@
+ALTER TABLE gear DROP COLUMN IF EXISTS image_renditions;
+
+ALTER TABLE gear_image DROP COLUMN IF EXISTS renditions;
@@ -0,0 +1,3 @@
+ALTER TABLE gear_image ADD COLUMN renditions JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE gear ADD COLUMN image_renditions JSONB NOT NULL DEFAULT '[]';
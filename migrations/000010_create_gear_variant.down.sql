@@ -0,0 +1,11 @@
+DELETE FROM gear_order WHERE variant_id IS NOT NULL;
+
+DROP INDEX IF EXISTS gear_order_line_idx;
+
+ALTER TABLE gear_order ADD PRIMARY KEY (order_id, gear_id);
+
+ALTER TABLE gear_order DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS gear_variant;
+
+ALTER TABLE gear DROP COLUMN IF EXISTS variant_axes;
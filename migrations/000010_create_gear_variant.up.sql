@@ -0,0 +1,27 @@
+ALTER TABLE gear ADD COLUMN variant_axes JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE gear_variant (
+    id UUID PRIMARY KEY,
+    gear_id UUID NOT NULL REFERENCES gear (id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    options JSONB NOT NULL DEFAULT '{}',
+    price DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (price >= 0),
+    discount DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (discount >= 0 AND discount <= 100),
+    quantity BIGINT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    image_id UUID REFERENCES gear_image (id) ON DELETE SET NULL,
+    position BIGINT NOT NULL DEFAULT 0,
+    UNIQUE (gear_id, options)
+);
+
+-- a cart can hold several variants of the same gear, a line is now unique
+-- per (order, gear, variant). Lines of ordered variants keep the variant
+-- from being deleted.
+ALTER TABLE gear_order ADD COLUMN variant_id UUID REFERENCES gear_variant (id);
+
+ALTER TABLE gear_order DROP CONSTRAINT IF EXISTS gear_order_pkey;
+
+CREATE UNIQUE INDEX gear_order_line_idx ON gear_order (
+    order_id,
+    gear_id,
+    coalesce(variant_id, '00000000-0000-0000-0000-000000000000')
+);
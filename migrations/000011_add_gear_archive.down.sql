@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS gear_active_type_idx;
+
+ALTER TABLE gear DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,4 @@
+ALTER TABLE gear ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- the lists only ever read the active gear
+CREATE INDEX gear_active_type_idx ON gear (type) WHERE archived_at IS NULL;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS gear_variant_history_update ON gear_variant;
+DROP TRIGGER IF EXISTS gear_variant_history_insert ON gear_variant;
+DROP TRIGGER IF EXISTS gear_history_update ON gear;
+DROP TRIGGER IF EXISTS gear_history_insert ON gear;
+
+DROP FUNCTION IF EXISTS record_gear_history();
+
+DROP TABLE IF EXISTS gear_history;
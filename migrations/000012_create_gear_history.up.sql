@@ -0,0 +1,81 @@
+-- append-only log of the price, discount and stock of gear and variants. It
+-- is written by triggers so no code path can change them unrecorded, the
+-- actor and reason are read from the gear_manager.actor_id and
+-- gear_manager.reason settings of the transaction.
+CREATE TABLE gear_history (
+    id BIGSERIAL PRIMARY KEY,
+    gear_id UUID NOT NULL REFERENCES gear (id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES gear_variant (id) ON DELETE SET NULL,
+    price DOUBLE PRECISION NOT NULL,
+    discount DOUBLE PRECISION NOT NULL,
+    quantity BIGINT NOT NULL,
+    old_price DOUBLE PRECISION,
+    old_discount DOUBLE PRECISION,
+    old_quantity BIGINT,
+    actor_id UUID,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX gear_history_gear_id_idx ON gear_history (gear_id, changed_at);
+
+CREATE FUNCTION record_gear_history() RETURNS trigger AS $$
+DECLARE
+    history_gear_id UUID;
+    history_variant_id UUID;
+BEGIN
+    IF TG_TABLE_NAME = 'gear_variant' THEN
+        history_gear_id := NEW.gear_id;
+        history_variant_id := NEW.id;
+    ELSE
+        history_gear_id := NEW.id;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO gear_history (gear_id, variant_id, price, discount, quantity, actor_id, reason)
+        VALUES (
+            history_gear_id, history_variant_id, NEW.price, NEW.discount, NEW.quantity,
+            nullif(current_setting('gear_manager.actor_id', true), '')::uuid,
+            coalesce(current_setting('gear_manager.reason', true), '')
+        );
+    ELSE
+        INSERT INTO gear_history (
+            gear_id, variant_id, price, discount, quantity,
+            old_price, old_discount, old_quantity, actor_id, reason
+        )
+        VALUES (
+            history_gear_id, history_variant_id, NEW.price, NEW.discount, NEW.quantity,
+            OLD.price, OLD.discount, OLD.quantity,
+            nullif(current_setting('gear_manager.actor_id', true), '')::uuid,
+            coalesce(current_setting('gear_manager.reason', true), '')
+        );
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER gear_history_insert AFTER INSERT ON gear
+FOR EACH ROW EXECUTE FUNCTION record_gear_history();
+
+CREATE TRIGGER gear_history_update AFTER UPDATE OF price, discount, quantity ON gear
+FOR EACH ROW
+WHEN (OLD.price IS DISTINCT FROM NEW.price
+    OR OLD.discount IS DISTINCT FROM NEW.discount
+    OR OLD.quantity IS DISTINCT FROM NEW.quantity)
+EXECUTE FUNCTION record_gear_history();
+
+CREATE TRIGGER gear_variant_history_insert AFTER INSERT ON gear_variant
+FOR EACH ROW EXECUTE FUNCTION record_gear_history();
+
+CREATE TRIGGER gear_variant_history_update AFTER UPDATE OF price, discount, quantity ON gear_variant
+FOR EACH ROW
+WHEN (OLD.price IS DISTINCT FROM NEW.price
+    OR OLD.discount IS DISTINCT FROM NEW.discount
+    OR OLD.quantity IS DISTINCT FROM NEW.quantity)
+EXECUTE FUNCTION record_gear_history();
+
+-- the current values start the history of the existing gear
+INSERT INTO gear_history (gear_id, price, discount, quantity, reason)
+SELECT id, price, discount, quantity, 'initial'
+FROM gear;
@@ -0,0 +1 @@
+ALTER TABLE gear DROP COLUMN IF EXISTS sku;
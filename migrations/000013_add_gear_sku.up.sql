@@ -0,0 +1,3 @@
+-- the stable key of the gear in catalog imports, 000017 makes it required
+-- and gear added without one is keyed by its id
+ALTER TABLE gear ADD COLUMN sku TEXT UNIQUE;
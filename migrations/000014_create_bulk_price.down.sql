@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bulk_price_change;
+
+DROP TABLE IF EXISTS bulk_price_operation;
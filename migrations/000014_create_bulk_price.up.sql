@@ -0,0 +1,20 @@
+CREATE TABLE bulk_price_operation (
+    id UUID PRIMARY KEY,
+    rule JSONB NOT NULL,
+    actor_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    undone_at TIMESTAMPTZ
+);
+
+-- the previous values of every gear, or variant, changed by an operation
+CREATE TABLE bulk_price_change (
+    operation_id UUID NOT NULL REFERENCES bulk_price_operation (id) ON DELETE CASCADE,
+    gear_id UUID NOT NULL REFERENCES gear (id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES gear_variant (id) ON DELETE CASCADE,
+    old_price DOUBLE PRECISION NOT NULL,
+    old_discount DOUBLE PRECISION NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    discount DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX bulk_price_change_operation_id_idx ON bulk_price_change (operation_id);
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS gear_promotion(UUID, TEXT, TEXT);
+DROP FUNCTION IF EXISTS promotion_discount(UUID, TEXT, TEXT);
+DROP FUNCTION IF EXISTS running_promotion(UUID, TEXT, TEXT);
+
+DROP TABLE IF EXISTS promotion;
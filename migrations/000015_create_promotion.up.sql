@@ -0,0 +1,54 @@
+-- a promotion discounts the gear it targets, by id, brand or category
+-- (subcategories included, 'all' for every gear), between starts_at and
+-- ends_at. Nothing is written when it starts or ends, the running promotions
+-- are looked up with now() whenever gear is read.
+CREATE TABLE promotion (
+    id UUID PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    discount DOUBLE PRECISION NOT NULL CHECK (discount > 0 AND discount <= 100),
+    priority BIGINT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    gear_ids UUID[] NOT NULL DEFAULT '{}',
+    brands TEXT[] NOT NULL DEFAULT '{}',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX promotion_period_idx ON promotion (ends_at, starts_at);
+
+-- running_promotion is the promotion applying to the gear now. When several
+-- run the highest priority wins, then the biggest discount, then the oldest.
+CREATE FUNCTION running_promotion(promoted_gear_id UUID, promoted_brand TEXT, promoted_type TEXT)
+RETURNS SETOF promotion AS $$
+    WITH RECURSIVE ancestor AS (
+        SELECT id, parent_id, key FROM category WHERE code = promoted_type
+        UNION ALL
+        SELECT c.id, c.parent_id, c.key FROM category c JOIN ancestor a ON c.id = a.parent_id
+    )
+    SELECT p.* FROM promotion p
+    WHERE p.starts_at <= now() AND now() < p.ends_at
+        AND (promoted_gear_id = ANY(p.gear_ids)
+            OR promoted_brand = ANY(p.brands)
+            OR 'all' = ANY(p.categories)
+            OR p.categories && ARRAY(SELECT key::text FROM ancestor))
+    ORDER BY p.priority DESC, p.discount DESC, p.created_at, p.id
+    LIMIT 1
+$$ LANGUAGE sql STABLE;
+
+-- promotion_discount is the discount of the running promotion, 0 without one
+CREATE FUNCTION promotion_discount(promoted_gear_id UUID, promoted_brand TEXT, promoted_type TEXT)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT coalesce(
+        (SELECT discount FROM running_promotion(promoted_gear_id, promoted_brand, promoted_type)),
+        0
+    )
+$$ LANGUAGE sql STABLE;
+
+-- gear_promotion is the running promotion as shown with the gear, or NULL
+CREATE FUNCTION gear_promotion(promoted_gear_id UUID, promoted_brand TEXT, promoted_type TEXT)
+RETURNS JSONB AS $$
+    SELECT jsonb_build_object('id', id, 'name', name, 'discount', discount, 'ends_at', ends_at)
+    FROM running_promotion(promoted_gear_id, promoted_brand, promoted_type)
+$$ LANGUAGE sql STABLE;
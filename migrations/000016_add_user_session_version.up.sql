@@ -0,0 +1,4 @@
+-- session_version is raised to end the sessions of a user, the refresh token
+-- carries the version it was issued for
+ALTER TABLE "user"
+    ADD COLUMN session_version INTEGER NOT NULL DEFAULT 0;
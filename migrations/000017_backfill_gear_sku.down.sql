@@ -0,0 +1 @@
+ALTER TABLE gear ALTER COLUMN sku DROP NOT NULL;
@@ -0,0 +1,5 @@
+-- gear without a sku is keyed by its id, so an exported catalog can always be
+-- imported back
+UPDATE gear SET sku=id::text WHERE sku IS NULL;
+
+ALTER TABLE gear ALTER COLUMN sku SET NOT NULL;
@@ -0,0 +1,7 @@
+UPDATE gear_history SET variant_id=NULL
+WHERE variant_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM gear_variant WHERE id=gear_history.variant_id);
+
+ALTER TABLE gear_history
+    ADD CONSTRAINT gear_history_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES gear_variant (id) ON DELETE SET NULL;
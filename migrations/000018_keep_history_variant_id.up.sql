@@ -0,0 +1,3 @@
+-- the history of a deleted variant keeps its variant id, it must not turn
+-- into gear level history and count toward the lowest price of the gear
+ALTER TABLE gear_history DROP CONSTRAINT gear_history_variant_id_fkey;
@@ -0,0 +1,5 @@
+-- the running promotion of a gear is resolved by the gear queries, once per
+-- query rather than once per row
+DROP FUNCTION IF EXISTS gear_promotion(UUID, TEXT, TEXT);
+DROP FUNCTION IF EXISTS promotion_discount(UUID, TEXT, TEXT);
+DROP FUNCTION IF EXISTS running_promotion(UUID, TEXT, TEXT);
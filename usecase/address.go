@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
 )
 
 type AddressRepository interface {
@@ -24,17 +25,35 @@ func NewAddressUsecase(r AddressRepository) *AddressUsecase {
 	}
 }
 
-func (u *AddressUsecase) GetAddressByID(ctx context.Context, id string) (*domain.Address, error) {
+func (u *AddressUsecase) GetAddressByID(ctx context.Context, user *domain.UserInfo, id string) (*domain.Address, error) {
 	result, err := u.r.GetAddressByID(ctx, id)
 
 	if err != nil {
 		return nil, err
 	}
 
+	err = authorize(user, result.UserID, domain.PermissionManageUser)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return result, err
 }
 
-func (u *AddressUsecase) GetAddressList(ctx context.Context, userID string) ([]*domain.Address, error) {
+func (u *AddressUsecase) GetAddressList(ctx context.Context, user *domain.UserInfo, userID string) ([]*domain.Address, error) {
+	ownerID, err := uuid.Parse(userID)
+
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	err = authorize(user, ownerID, domain.PermissionManageUser)
+
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := u.r.GetAddressList(ctx, userID)
 
 	if err != nil {
@@ -54,8 +73,14 @@ func (u *AddressUsecase) AddAddress(ctx context.Context, userID string, f *domai
 	return nil
 }
 
-func (u *AddressUsecase) UpdateAddress(ctx context.Context, id string, f *domain.UpdateAddressForm) error {
-	err := u.r.UpdateAddress(ctx, id, f)
+func (u *AddressUsecase) UpdateAddress(ctx context.Context, user *domain.UserInfo, id string, f *domain.UpdateAddressForm) error {
+	_, err := u.GetAddressByID(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	err = u.r.UpdateAddress(ctx, id, f)
 
 	if err != nil {
 		return err
@@ -64,8 +89,14 @@ func (u *AddressUsecase) UpdateAddress(ctx context.Context, id string, f *domain
 	return nil
 }
 
-func (u *AddressUsecase) DeleteAddress(ctx context.Context, id string) error {
-	err := u.r.DeleteAddress(ctx, id)
+func (u *AddressUsecase) DeleteAddress(ctx context.Context, user *domain.UserInfo, id string) error {
+	_, err := u.GetAddressByID(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	err = u.r.DeleteAddress(ctx, id)
 
 	if err != nil {
 		return err
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+)
+
+// authorize lets the owner of a resource through, as well as any user whose
+// role grants p
+func authorize(user *domain.UserInfo, ownerID uuid.UUID, p domain.Permission) error {
+	if user == nil {
+		return domain.ErrForbidden
+	}
+
+	if user.ID == ownerID || user.Role.Can(p) {
+		return nil
+	}
+
+	return domain.ErrForbidden
+}
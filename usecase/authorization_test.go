@@ -0,0 +1,285 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/goldenfealla/gear-manager/usecase"
+	"github.com/google/uuid"
+)
+
+var (
+	owner    = &domain.UserInfo{ID: uuid.New(), Role: domain.CUSTOMER}
+	customer = &domain.UserInfo{ID: uuid.New(), Role: domain.CUSTOMER}
+	admin    = &domain.UserInfo{ID: uuid.New(), Role: domain.ADMIN}
+)
+
+// fakeTransactor runs fn without a transaction
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeOrderRepository holds the orders in memory, the methods the tests
+// don't reach panic through the nil embedded interface
+type fakeOrderRepository struct {
+	usecase.OrderRepository
+	orders map[string]*domain.FullOrder
+}
+
+func (r *fakeOrderRepository) GetFullOrderByID(ctx context.Context, orderID string) (*domain.FullOrder, error) {
+	order, ok := r.orders[orderID]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return order, nil
+}
+
+func (r *fakeOrderRepository) GetOrderForUpdate(ctx context.Context, orderID string) (*domain.Order, error) {
+	order, err := r.GetFullOrderByID(ctx, orderID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return order.Order, nil
+}
+
+func (r *fakeOrderRepository) UpdateOrderTotalPrice(ctx context.Context, orderID string, price int64) error {
+	r.orders[orderID].Order.Total = price
+	return nil
+}
+
+func (r *fakeOrderRepository) UpdateOrderStatus(ctx context.Context, orderID string, status domain.OrderStatus) error {
+	r.orders[orderID].Order.Status = status
+	return nil
+}
+
+type fakeGearRepository struct {
+	usecase.GearRepository
+}
+
+func (r *fakeGearRepository) DecreaseGearQuantity(ctx context.Context, id string, quantity int64) (int64, error) {
+	return 10, nil
+}
+
+type fakeAddressRepository struct {
+	addresses map[string]*domain.Address
+	updated   bool
+	deleted   bool
+}
+
+func (r *fakeAddressRepository) GetAddressByID(ctx context.Context, id string) (*domain.Address, error) {
+	address, ok := r.addresses[id]
+
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return address, nil
+}
+
+func (r *fakeAddressRepository) GetAddressList(ctx context.Context, userID string) ([]*domain.Address, error) {
+	result := []*domain.Address{}
+
+	for _, a := range r.addresses {
+		if a.UserID.String() == userID {
+			result = append(result, a)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *fakeAddressRepository) AddAddress(ctx context.Context, userID string, a *domain.AddAddressForm) error {
+	return nil
+}
+
+func (r *fakeAddressRepository) UpdateAddress(ctx context.Context, id string, a *domain.UpdateAddressForm) error {
+	r.updated = true
+	return nil
+}
+
+func (r *fakeAddressRepository) DeleteAddress(ctx context.Context, id string) error {
+	r.deleted = true
+	return nil
+}
+
+func newOrderUsecase() (*usecase.OrderUsercase, string) {
+	id := uuid.New()
+
+	or := &fakeOrderRepository{
+		orders: map[string]*domain.FullOrder{
+			id.String(): {
+				Order: &domain.Order{ID: id, Status: domain.CART, UserID: owner.ID},
+				OrderGear: []*domain.OrderGear{
+					{
+						Gear:     &domain.Gear{ID: uuid.New(), Name: "Tent", Price: 100, Quantity: 10},
+						Quantity: 2,
+					},
+				},
+			},
+		},
+	}
+
+	return usecase.NewOrderUsercase(or, nil, &fakeGearRepository{}, fakeTransactor{}), id.String()
+}
+
+func newAddressUsecase() (*usecase.AddressUsecase, *fakeAddressRepository, string) {
+	id := uuid.New()
+
+	r := &fakeAddressRepository{
+		addresses: map[string]*domain.Address{
+			id.String(): {ID: id, UserID: owner.ID, Address: "1 Main St", Country: "VN"},
+		},
+	}
+
+	return usecase.NewAddressUsecase(r), r, id.String()
+}
+
+// accessCases are the users trying to reach a resource of owner, with the
+// id they ask for and the error they get
+func accessCases(id string) []struct {
+	name string
+	user *domain.UserInfo
+	id   string
+	want error
+} {
+	return []struct {
+		name string
+		user *domain.UserInfo
+		id   string
+		want error
+	}{
+		{"owner", owner, id, nil},
+		{"other customer", customer, id, domain.ErrForbidden},
+		{"admin", admin, id, nil},
+		{"missing id", owner, uuid.NewString(), domain.ErrNotFound},
+		{"malformed id", owner, "not-a-uuid", domain.ErrNotFound},
+	}
+}
+
+func TestGetOrder(t *testing.T) {
+	u, id := newOrderUsecase()
+
+	for _, tc := range accessCases(id) {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := u.GetOrder(context.Background(), tc.user, tc.id)
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestPayCart(t *testing.T) {
+	for _, tc := range accessCases("") {
+		t.Run(tc.name, func(t *testing.T) {
+			u, id := newOrderUsecase()
+
+			if tc.id != "" {
+				id = tc.id
+			}
+
+			err := u.PayCart(context.Background(), tc.user, id)
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetAddressByID(t *testing.T) {
+	u, _, id := newAddressUsecase()
+
+	for _, tc := range accessCases(id) {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := u.GetAddressByID(context.Background(), tc.user, tc.id)
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAddress(t *testing.T) {
+	for _, tc := range accessCases("") {
+		t.Run(tc.name, func(t *testing.T) {
+			u, r, id := newAddressUsecase()
+
+			if tc.id != "" {
+				id = tc.id
+			}
+
+			err := u.UpdateAddress(context.Background(), tc.user, id, &domain.UpdateAddressForm{})
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+
+			if r.updated != (tc.want == nil) {
+				t.Fatalf("updated is %v, want %v", r.updated, tc.want == nil)
+			}
+		})
+	}
+}
+
+func TestDeleteAddress(t *testing.T) {
+	for _, tc := range accessCases("") {
+		t.Run(tc.name, func(t *testing.T) {
+			u, r, id := newAddressUsecase()
+
+			if tc.id != "" {
+				id = tc.id
+			}
+
+			err := u.DeleteAddress(context.Background(), tc.user, id)
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+
+			if r.deleted != (tc.want == nil) {
+				t.Fatalf("deleted is %v, want %v", r.deleted, tc.want == nil)
+			}
+		})
+	}
+}
+
+func TestGetAddressList(t *testing.T) {
+	u, _, _ := newAddressUsecase()
+
+	cases := []struct {
+		name   string
+		user   *domain.UserInfo
+		userID string
+		want   error
+	}{
+		{"owner", owner, owner.ID.String(), nil},
+		{"other customer", customer, owner.ID.String(), domain.ErrForbidden},
+		{"admin", admin, owner.ID.String(), nil},
+		{"malformed id", owner, "not-a-uuid", domain.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := u.GetAddressList(context.Background(), tc.user, tc.userID)
+
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+
+			if tc.want == nil && len(result) != 1 {
+				t.Fatalf("got %v addresses, want 1", len(result))
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+)
+
+type BuildRepository interface {
+	GetBuildByID(ctx context.Context, id string) (*domain.Build, error)
+	GetBuildByShareToken(ctx context.Context, token string) (*domain.Build, error)
+	GetBuildList(ctx context.Context, userID string) ([]*domain.Build, error)
+	AddBuild(ctx context.Context, userID uuid.UUID, f *domain.AddBuildForm) (uuid.UUID, error)
+	CloneBuild(ctx context.Context, id uuid.UUID, userID uuid.UUID, name string) (uuid.UUID, error)
+	UpdateBuild(ctx context.Context, id string, f *domain.UpdateBuildForm) error
+	SetBuildShareToken(ctx context.Context, id string, token *string) error
+	SetBuildSlot(ctx context.Context, id uuid.UUID, slot string, gearID uuid.UUID, quantity int64) error
+	RemoveBuildSlot(ctx context.Context, id uuid.UUID, slot string) error
+	DeleteBuild(ctx context.Context, id uuid.UUID) error
+}
+
+type BuildUsecase struct {
+	r  BuildRepository
+	or OrderRepository
+	gr GearRepository
+	cr CategoryRepository
+	tx Transactor
+}
+
+func NewBuildUsecase(r BuildRepository, or OrderRepository, gr GearRepository, cr CategoryRepository, tx Transactor) *BuildUsecase {
+	return &BuildUsecase{
+		r,
+		or,
+		gr,
+		cr,
+		tx,
+	}
+}
+
+// getOwnBuild returns the build id when user may change it
+func (u *BuildUsecase) getOwnBuild(ctx context.Context, user *domain.UserInfo, id string) (*domain.Build, error) {
+	build, err := u.r.GetBuildByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(user, build.UserID, domain.PermissionManageUser)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return build, nil
+}
+
+func (u *BuildUsecase) GetBuild(ctx context.Context, user *domain.UserInfo, id string) (*domain.Build, error) {
+	return u.getOwnBuild(ctx, user, id)
+}
+
+// GetSharedBuild returns the build shared under token to anyone, the token
+// itself is left out so it can't be revoked by a visitor resharing it
+func (u *BuildUsecase) GetSharedBuild(ctx context.Context, token string) (*domain.Build, error) {
+	build, err := u.r.GetBuildByShareToken(ctx, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	build.ShareToken = nil
+
+	return build, nil
+}
+
+func (u *BuildUsecase) GetBuildList(ctx context.Context, user *domain.UserInfo) ([]*domain.Build, error) {
+	result, err := u.r.GetBuildList(ctx, user.ID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+func (u *BuildUsecase) AddBuild(ctx context.Context, user *domain.UserInfo, f *domain.AddBuildForm) (*domain.Build, error) {
+	id, err := u.r.AddBuild(ctx, user.ID, f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetBuildByID(ctx, id.String())
+}
+
+// CloneBuild copies a build of user, or the build shared under token when
+// id is empty, into a new build of user
+func (u *BuildUsecase) CloneBuild(ctx context.Context, user *domain.UserInfo, id string, token string) (*domain.Build, error) {
+	var build *domain.Build
+	var err error
+
+	if id != "" {
+		build, err = u.getOwnBuild(ctx, user, id)
+	} else {
+		build, err = u.r.GetBuildByShareToken(ctx, token)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	newID, err := u.r.CloneBuild(ctx, build.ID, user.ID, build.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetBuildByID(ctx, newID.String())
+}
+
+func (u *BuildUsecase) UpdateBuild(ctx context.Context, user *domain.UserInfo, id string, f *domain.UpdateBuildForm) error {
+	_, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.r.UpdateBuild(ctx, id, f)
+}
+
+// ShareBuild gives the build a new share token and returns it, a previous
+// link stops working
+func (u *BuildUsecase) ShareBuild(ctx context.Context, user *domain.UserInfo, id string) (string, error) {
+	_, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+
+	if err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err = u.r.SetBuildShareToken(ctx, id, &token)
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (u *BuildUsecase) UnshareBuild(ctx context.Context, user *domain.UserInfo, id string) error {
+	_, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.r.SetBuildShareToken(ctx, id, nil)
+}
+
+// SetBuildSlot puts a gear in a slot, the slot is a category key and the gear
+// must belong to that category or one of its subcategories
+func (u *BuildUsecase) SetBuildSlot(ctx context.Context, user *domain.UserInfo, id string, f *domain.SetBuildSlotForm) error {
+	build, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	codes, err := u.cr.GetCategoryCodes(ctx, f.Slot)
+
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && codes == nil) {
+		return fmt.Errorf("slot %v is not a category", f.Slot)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	gear, err := u.gr.GetGearByID(ctx, f.GearID)
+
+	if err != nil {
+		return err
+	}
+
+	if gear.Archived() {
+		return domain.ErrGearArchived
+	}
+
+	if !slices.Contains(codes, gear.Type) {
+		return fmt.Errorf("%v can't go in slot %v", gear.Name, f.Slot)
+	}
+
+	quantity := f.Quantity
+
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	return u.r.SetBuildSlot(ctx, build.ID, f.Slot, gear.ID, quantity)
+}
+
+func (u *BuildUsecase) RemoveBuildSlot(ctx context.Context, user *domain.UserInfo, id string, slot string) error {
+	build, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.r.RemoveBuildSlot(ctx, build.ID, slot)
+}
+
+func (u *BuildUsecase) DeleteBuild(ctx context.Context, user *domain.UserInfo, id string) error {
+	build, err := u.getOwnBuild(ctx, user, id)
+
+	if err != nil {
+		return err
+	}
+
+	return u.r.DeleteBuild(ctx, build.ID)
+}
+
+// AddBuildToCart adds every part of the build to the cart of user in one
+// transaction, parts already in the cart get their quantity raised
+func (u *BuildUsecase) AddBuildToCart(ctx context.Context, user *domain.UserInfo, id string) error {
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		build, err := u.getOwnBuild(ctx, user, id)
+		if err != nil {
+			return err
+		}
+
+		if len(build.Slots) == 0 {
+			return errors.New("build is empty")
+		}
+
+		userID := user.ID.String()
+
+		if !u.or.HasCart(ctx, userID) {
+			err := u.or.CreateCart(ctx, userID)
+			if err != nil {
+				return err
+			}
+		}
+
+		cart, err := u.or.GetFullCartByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		quantities := map[uuid.UUID]int64{}
+
+		for _, og := range cart.OrderGear {
+			if og.Variant == nil {
+				quantities[og.Gear.ID] = og.Quantity
+			}
+		}
+
+		for _, s := range build.Slots {
+			if s.Gear.Archived() {
+				return fmt.Errorf("%v: %w", s.Gear.Name, domain.ErrGearArchived)
+			}
+
+			// a build slot holds a gear, not one of its variants
+			if s.Gear.HasVariants() {
+				return fmt.Errorf("%v: %w", s.Gear.Name, domain.ErrVariantRequired)
+			}
+
+			gearID := s.Gear.ID.String()
+			quantity, inCart := quantities[s.Gear.ID]
+
+			if !inCart {
+				err := u.or.AddProductToCart(ctx, cart.Order, gearID, nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			quantity += s.Quantity
+			quantities[s.Gear.ID] = quantity
+
+			err := u.or.SetGearQuantityCart(ctx, cart.Order, gearID, nil, quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
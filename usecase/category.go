@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+)
+
+type CategoryRepository interface {
+	GetCategoryTree(ctx context.Context) ([]*domain.Category, error)
+	GetCategoryByKey(ctx context.Context, key string) (*domain.Category, error)
+	IsDescendant(ctx context.Context, key string, id uuid.UUID) (bool, error)
+	AddCategory(ctx context.Context, f *domain.AddCategoryForm, parentID *uuid.UUID) error
+	UpdateCategory(ctx context.Context, id uuid.UUID, f *domain.UpdateCategoryForm, setParent bool, parentID *uuid.UUID) error
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	CategoryKeyOf(ctx context.Context, code string) (string, error)
+	GetCategoryCodes(ctx context.Context, key string) ([]string, error)
+	GetCategoryAttributes(ctx context.Context, key string) ([]*domain.Attribute, error)
+	GetFilterAttributes(ctx context.Context, key string) ([]*domain.Attribute, error)
+	AddAttribute(ctx context.Context, categoryID uuid.UUID, f *domain.AddAttributeForm) error
+	UpdateAttribute(ctx context.Context, id uuid.UUID, f *domain.UpdateAttributeForm) error
+	DeleteAttribute(ctx context.Context, id uuid.UUID, codes []string) error
+}
+
+type CategoryUsecase struct {
+	r CategoryRepository
+}
+
+func NewCategoryUsecase(r CategoryRepository) *CategoryUsecase {
+	return &CategoryUsecase{
+		r,
+	}
+}
+
+func (u *CategoryUsecase) GetCategoryTree(ctx context.Context) ([]*domain.Category, error) {
+	result, err := u.r.GetCategoryTree(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+func (u *CategoryUsecase) GetCategoryByKey(ctx context.Context, key string) (*domain.Category, error) {
+	result, err := u.r.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+func (u *CategoryUsecase) AddCategory(ctx context.Context, f *domain.AddCategoryForm) error {
+	_, err := u.r.GetCategoryByKey(ctx, f.Key)
+
+	if err == nil {
+		return errors.New("category key has already been used")
+	}
+
+	var parentID *uuid.UUID
+
+	if f.ParentKey != nil && *f.ParentKey != "" {
+		parent, err := u.r.GetCategoryByKey(ctx, *f.ParentKey)
+
+		if err != nil {
+			return errors.New("parent category not exist")
+		}
+
+		parentID = &parent.ID
+	}
+
+	err = u.r.AddCategory(ctx, f, parentID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *CategoryUsecase) UpdateCategory(ctx context.Context, key string, f *domain.UpdateCategoryForm) error {
+	category, err := u.r.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	var parentID *uuid.UUID
+	setParent := f.ParentKey != nil
+
+	if setParent && *f.ParentKey != "" {
+		parent, err := u.r.GetCategoryByKey(ctx, *f.ParentKey)
+
+		if err != nil {
+			return errors.New("parent category not exist")
+		}
+
+		// moving a category under itself or one of its children would
+		// detach the whole branch from the tree
+		cycle, err := u.r.IsDescendant(ctx, category.Key, parent.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if cycle {
+			return errors.New("category can't be moved under itself or its subcategories")
+		}
+
+		parentID = &parent.ID
+	}
+
+	err = u.r.UpdateCategory(ctx, category.ID, f, setParent, parentID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *CategoryUsecase) DeleteCategory(ctx context.Context, key string) error {
+	category, err := u.r.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	err = u.r.DeleteCategory(ctx, category.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *CategoryUsecase) GetCategoryAttributes(ctx context.Context, key string) ([]*domain.Attribute, error) {
+	result, err := u.r.GetCategoryAttributes(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+// getOwnAttribute finds the attribute attributeKey declared by the category
+// key itself, inherited attributes must be changed on their own category
+func (u *CategoryUsecase) getOwnAttribute(ctx context.Context, key string, attributeKey string) (*domain.Attribute, error) {
+	category, err := u.r.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := u.r.GetCategoryAttributes(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attributes {
+		if a.Key == attributeKey && a.CategoryID == category.ID {
+			return a, nil
+		}
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (u *CategoryUsecase) AddAttribute(ctx context.Context, key string, f *domain.AddAttributeForm) error {
+	category, err := u.r.GetCategoryByKey(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	if f.Type != domain.AttributeEnum && len(f.Options) > 0 {
+		return errors.New("only enum attributes have options")
+	}
+
+	// keys must stay unique along the branch so a spec filter on a parent
+	// category means the same thing for all of its subcategories
+	attributes, err := u.r.GetFilterAttributes(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	for _, a := range attributes {
+		if a.Key == f.Key {
+			return errors.New("attribute key has already been used in this category, its parents or its subcategories")
+		}
+	}
+
+	err = u.r.AddAttribute(ctx, category.ID, f)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *CategoryUsecase) UpdateAttribute(ctx context.Context, key string, attributeKey string, f *domain.UpdateAttributeForm) error {
+	attribute, err := u.getOwnAttribute(ctx, key, attributeKey)
+
+	if err != nil {
+		return err
+	}
+
+	if f.Options != nil && attribute.Type != domain.AttributeEnum {
+		return errors.New("only enum attributes have options")
+	}
+
+	if f.Options != nil && len(*f.Options) == 0 {
+		return errors.New("enum attribute options can't be empty")
+	}
+
+	err = u.r.UpdateAttribute(ctx, attribute.ID, f)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAttribute also removes the attribute from the specs of the gear of
+// the category and its subcategories
+func (u *CategoryUsecase) DeleteAttribute(ctx context.Context, key string, attributeKey string) error {
+	attribute, err := u.getOwnAttribute(ctx, key, attributeKey)
+
+	if err != nil {
+		return err
+	}
+
+	codes, err := u.r.GetCategoryCodes(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	err = u.r.DeleteAttribute(ctx, attribute.ID, codes)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
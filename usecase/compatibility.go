@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// basePowerDraw estimates the watts drawn by everything but the CPU and the
+// GPU, e.g. the mainboard, RAM, storage and fans
+const basePowerDraw = 100.0
+
+// psuHeadroom is the share of the PSU rating above which a build is only
+// warned, a PSU running near its limit is loud and inefficient
+const psuHeadroom = 0.8
+
+// buildParts groups the gear of a build by category key, a gear picked
+// twice appears twice
+type buildParts map[string][]*domain.Gear
+
+// compatibilityRule checks the parts of a build, it returns no result when
+// the build doesn't have the parts the rule is about
+type compatibilityRule func(p buildParts) []*domain.CompatibilityResult
+
+var compatibilityRules = []compatibilityRule{
+	checkPartCount,
+	checkSocket,
+	checkMemoryType,
+	checkPower,
+	checkGPULength,
+}
+
+// checkCompatibility runs every rule over parts
+func checkCompatibility(parts buildParts) *domain.CompatibilityReport {
+	report := &domain.CompatibilityReport{
+		Status:  domain.CompatibilityPass,
+		Results: []*domain.CompatibilityResult{},
+	}
+
+	for _, rule := range compatibilityRules {
+		for _, r := range rule(parts) {
+			report.Status = report.Status.Worse(r.Status)
+			report.Results = append(report.Results, r)
+		}
+	}
+
+	return report
+}
+
+func specString(g *domain.Gear, key string) (string, bool) {
+	v, ok := g.Specs[key].(string)
+
+	return v, ok
+}
+
+func specNumber(g *domain.Gear, key string) (float64, bool) {
+	v, ok := g.Specs[key].(float64)
+
+	return v, ok
+}
+
+// matchSpec checks that a and b have the same value for the spec key
+func matchSpec(rule string, key string, a *domain.Gear, b *domain.Gear) *domain.CompatibilityResult {
+	label := strings.ReplaceAll(key, "_", " ")
+	av, aok := specString(a, key)
+	bv, bok := specString(b, key)
+
+	switch {
+	case !aok || !bok:
+		missing := a
+
+		if aok {
+			missing = b
+		}
+
+		return &domain.CompatibilityResult{
+			Rule:    rule,
+			Status:  domain.CompatibilityWarn,
+			Message: fmt.Sprintf("can't check, %v has no %v", missing.Name, label),
+		}
+	case av != bv:
+		return &domain.CompatibilityResult{
+			Rule:    rule,
+			Status:  domain.CompatibilityFail,
+			Message: fmt.Sprintf("%v uses %v %v but %v uses %v", a.Name, label, av, b.Name, bv),
+		}
+	default:
+		return &domain.CompatibilityResult{
+			Rule:    rule,
+			Status:  domain.CompatibilityPass,
+			Message: fmt.Sprintf("%v and %v both use %v %v", a.Name, b.Name, label, av),
+		}
+	}
+}
+
+// checkPartCount warns about the parts a build only has one of
+func checkPartCount(p buildParts) []*domain.CompatibilityResult {
+	results := []*domain.CompatibilityResult{}
+
+	for _, key := range []string{"cpu", "mainboard", "psu", "case"} {
+		if len(p[key]) > 1 {
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "part_count",
+				Status:  domain.CompatibilityWarn,
+				Message: fmt.Sprintf("the build has %v parts of category %v, it only fits one", len(p[key]), key),
+			})
+		}
+	}
+
+	return results
+}
+
+func checkSocket(p buildParts) []*domain.CompatibilityResult {
+	results := []*domain.CompatibilityResult{}
+
+	for _, cpu := range p["cpu"] {
+		for _, board := range p["mainboard"] {
+			results = append(results, matchSpec("cpu_socket", "socket", cpu, board))
+		}
+	}
+
+	return results
+}
+
+func checkMemoryType(p buildParts) []*domain.CompatibilityResult {
+	results := []*domain.CompatibilityResult{}
+
+	for _, ram := range p["ram"] {
+		for _, board := range p["mainboard"] {
+			results = append(results, matchSpec("memory_type", "memory_type", ram, board))
+		}
+	}
+
+	return results
+}
+
+// checkPower compares the estimated draw of the build with the PSU rating
+func checkPower(p buildParts) []*domain.CompatibilityResult {
+	results := []*domain.CompatibilityResult{}
+	draw := basePowerDraw
+	unknown := []string{}
+
+	for _, g := range append(append([]*domain.Gear{}, p["cpu"]...), p["gpu"]...) {
+		tdp, ok := specNumber(g, "tdp")
+
+		if !ok {
+			unknown = append(unknown, g.Name)
+			continue
+		}
+
+		draw += tdp
+	}
+
+	for _, psu := range p["psu"] {
+		wattage, ok := specNumber(psu, "wattage")
+
+		switch {
+		case !ok:
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "power",
+				Status:  domain.CompatibilityWarn,
+				Message: fmt.Sprintf("can't check, %v has no wattage", psu.Name),
+			})
+		case draw > wattage:
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "power",
+				Status:  domain.CompatibilityFail,
+				Message: fmt.Sprintf("the build draws about %vW but %v is rated %vW", draw, psu.Name, wattage),
+			})
+		case draw > wattage*psuHeadroom:
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "power",
+				Status:  domain.CompatibilityWarn,
+				Message: fmt.Sprintf("the build draws about %vW, over %v%% of the %vW of %v", draw, psuHeadroom*100, wattage, psu.Name),
+			})
+		case len(unknown) > 0:
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "power",
+				Status:  domain.CompatibilityWarn,
+				Message: fmt.Sprintf("the build draws about %vW without %v which have no tdp, %v is rated %vW", draw, strings.Join(unknown, ", "), psu.Name, wattage),
+			})
+		default:
+			results = append(results, &domain.CompatibilityResult{
+				Rule:    "power",
+				Status:  domain.CompatibilityPass,
+				Message: fmt.Sprintf("the build draws about %vW, %v is rated %vW", draw, psu.Name, wattage),
+			})
+		}
+	}
+
+	return results
+}
+
+func checkGPULength(p buildParts) []*domain.CompatibilityResult {
+	results := []*domain.CompatibilityResult{}
+
+	for _, gpu := range p["gpu"] {
+		for _, c := range p["case"] {
+			length, lok := specNumber(gpu, "length")
+			max, mok := specNumber(c, "max_gpu_length")
+
+			switch {
+			case !lok:
+				results = append(results, &domain.CompatibilityResult{
+					Rule:    "gpu_length",
+					Status:  domain.CompatibilityWarn,
+					Message: fmt.Sprintf("can't check, %v has no length", gpu.Name),
+				})
+			case !mok:
+				results = append(results, &domain.CompatibilityResult{
+					Rule:    "gpu_length",
+					Status:  domain.CompatibilityWarn,
+					Message: fmt.Sprintf("can't check, %v has no max gpu length", c.Name),
+				})
+			case length > max:
+				results = append(results, &domain.CompatibilityResult{
+					Rule:    "gpu_length",
+					Status:  domain.CompatibilityFail,
+					Message: fmt.Sprintf("%v is %vmm long but %v fits up to %vmm", gpu.Name, length, c.Name, max),
+				})
+			default:
+				results = append(results, &domain.CompatibilityResult{
+					Rule:    "gpu_length",
+					Status:  domain.CompatibilityPass,
+					Message: fmt.Sprintf("%v (%vmm) fits in %v (up to %vmm)", gpu.Name, length, c.Name, max),
+				})
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+func part(name string, specs domain.Specs) *domain.Gear {
+	return &domain.Gear{Name: name, Specs: specs}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	cpu := part("CPU", domain.Specs{"socket": "AM5", "tdp": 120.0})
+	board := part("Board", domain.Specs{"socket": "AM5", "memory_type": "DDR5"})
+	intelBoard := part("Intel board", domain.Specs{"socket": "LGA1700", "memory_type": "DDR5"})
+	ddr5 := part("DDR5 kit", domain.Specs{"memory_type": "DDR5"})
+	ddr4 := part("DDR4 kit", domain.Specs{"memory_type": "DDR4"})
+	gpu := part("GPU", domain.Specs{"tdp": 300.0, "length": 320.0})
+	bare := part("Bare", domain.Specs{})
+
+	cases := []struct {
+		name   string
+		parts  buildParts
+		status domain.CompatibilityStatus
+		// rules are the rule and status of every result, in order
+		rules [][2]string
+	}{
+		{
+			name:   "no parts",
+			parts:  buildParts{},
+			status: domain.CompatibilityPass,
+		},
+		{
+			name:   "matching socket and memory",
+			parts:  buildParts{"cpu": {cpu}, "mainboard": {board}, "ram": {ddr5}},
+			status: domain.CompatibilityPass,
+			rules:  [][2]string{{"cpu_socket", "pass"}, {"memory_type", "pass"}},
+		},
+		{
+			name:   "socket mismatch",
+			parts:  buildParts{"cpu": {cpu}, "mainboard": {intelBoard}},
+			status: domain.CompatibilityFail,
+			rules:  [][2]string{{"cpu_socket", "fail"}},
+		},
+		{
+			name:   "memory mismatch",
+			parts:  buildParts{"ram": {ddr4}, "mainboard": {board}},
+			status: domain.CompatibilityFail,
+			rules:  [][2]string{{"memory_type", "fail"}},
+		},
+		{
+			name:   "missing socket spec",
+			parts:  buildParts{"cpu": {bare}, "mainboard": {board}},
+			status: domain.CompatibilityWarn,
+			rules:  [][2]string{{"cpu_socket", "warn"}},
+		},
+		{
+			name:   "two cpus",
+			parts:  buildParts{"cpu": {cpu, cpu}},
+			status: domain.CompatibilityWarn,
+			rules:  [][2]string{{"part_count", "warn"}},
+		},
+		{
+			name:   "psu with room",
+			parts:  buildParts{"cpu": {cpu}, "gpu": {gpu}, "psu": {part("PSU", domain.Specs{"wattage": 850.0})}},
+			status: domain.CompatibilityPass,
+			rules:  [][2]string{{"power", "pass"}},
+		},
+		{
+			name:   "psu near its limit",
+			parts:  buildParts{"cpu": {cpu}, "gpu": {gpu}, "psu": {part("PSU", domain.Specs{"wattage": 600.0})}},
+			status: domain.CompatibilityWarn,
+			rules:  [][2]string{{"power", "warn"}},
+		},
+		{
+			name:   "psu too small",
+			parts:  buildParts{"cpu": {cpu}, "gpu": {gpu}, "psu": {part("PSU", domain.Specs{"wattage": 500.0})}},
+			status: domain.CompatibilityFail,
+			rules:  [][2]string{{"power", "fail"}},
+		},
+		{
+			name:   "part without tdp",
+			parts:  buildParts{"cpu": {bare}, "psu": {part("PSU", domain.Specs{"wattage": 850.0})}},
+			status: domain.CompatibilityWarn,
+			rules:  [][2]string{{"power", "warn"}},
+		},
+		{
+			name:   "gpu fits",
+			parts:  buildParts{"gpu": {gpu}, "case": {part("Case", domain.Specs{"max_gpu_length": 340.0})}},
+			status: domain.CompatibilityPass,
+			rules:  [][2]string{{"gpu_length", "pass"}},
+		},
+		{
+			name:   "gpu too long",
+			parts:  buildParts{"gpu": {gpu}, "case": {part("Case", domain.Specs{"max_gpu_length": 300.0})}},
+			status: domain.CompatibilityFail,
+			rules:  [][2]string{{"gpu_length", "fail"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := checkCompatibility(tc.parts)
+
+			if report.Status != tc.status {
+				t.Fatalf("got status %v, want %v", report.Status, tc.status)
+			}
+
+			if len(report.Results) != len(tc.rules) {
+				t.Fatalf("got %v results, want %v: %+v", len(report.Results), len(tc.rules), report.Results)
+			}
+
+			for i, r := range report.Results {
+				if r.Rule != tc.rules[i][0] || string(r.Status) != tc.rules[i][1] {
+					t.Errorf("result %v is %v %v, want %v %v", i, r.Rule, r.Status, tc.rules[i][0], tc.rules[i][1])
+				}
+			}
+		})
+	}
+}
@@ -2,29 +2,65 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
 )
 
 type GearRepository interface {
 	GetGearVarietyList(ctx context.Context, category string) ([]string, error)
 	GetGearBrandList(ctx context.Context, category string) ([]string, error)
 	GetGearListCount(ctx context.Context, filter domain.ListGearFilter) (int64, error)
-	GetGearList(ctx context.Context, filter domain.ListGearFilter) ([]*domain.Gear, error)
+	GetGearList(ctx context.Context, filter domain.ListGearFilter) (*domain.Page[*domain.Gear], error)
+	GetGearFacets(ctx context.Context, filter domain.ListGearFilter, buckets []float64) (*domain.GearFacets, error)
+	GetGearSuggestion(ctx context.Context, filter domain.SuggestGearFilter) ([]*domain.GearSuggestion, error)
 	GetGearByID(ctx context.Context, id string) (*domain.Gear, error)
-	AddGear(ctx context.Context, g *domain.AddGearForm) error
-	UpdateGear(ctx context.Context, id string, g *domain.UpdateGearForm) error
+	GetGearListByIDs(ctx context.Context, ids []string) ([]*domain.Gear, error)
+	GetGearListBySKUs(ctx context.Context, skus []string) ([]*domain.Gear, error)
+	AddGear(ctx context.Context, g *domain.AddGearForm) (*domain.GearImage, error)
+	UpdateGear(ctx context.Context, id string, g *domain.UpdateGearForm) (*domain.GearImage, error)
 	UpdateGearQuantity(ctx context.Context, id string, quantity int64) error
-	DeleteGear(ctx context.Context, id string) error
+	DecreaseGearQuantity(ctx context.Context, id string, quantity int64) (int64, error)
+	ArchiveGear(ctx context.Context, id string) error
+	RestoreGear(ctx context.Context, id string) error
+	GetGearForUpdate(ctx context.Context, id string) (*domain.Gear, error)
+	IsGearOrdered(ctx context.Context, id string) (bool, error)
+	DeleteGear(ctx context.Context, id string) ([]*domain.GearImage, error)
+	GetGearImageList(ctx context.Context, gearID string) ([]*domain.GearImage, error)
+	AddGearImage(ctx context.Context, gearID string, img *domain.NewGearImage) (*domain.GearImage, error)
+	UpdateGearImage(ctx context.Context, imageID string, img *domain.UpdateGearImageForm) error
+	SetPrimaryGearImage(ctx context.Context, gearID string, imageID string) error
+	ReorderGearImages(ctx context.Context, gearID string, imageIDs []string) error
+	DeleteGearImage(ctx context.Context, gearID string, imageID string) (*domain.GearImage, error)
+	DeleteImageObjects(ctx context.Context, images []*domain.GearImage) error
+	ReconcileImages(ctx context.Context, dryRun bool) (*domain.ImageReconcileReport, error)
+	GetGearVariantList(ctx context.Context, gearID string) ([]*domain.GearVariant, error)
+	GetGearVariant(ctx context.Context, gearID string, variantID string) (*domain.GearVariant, error)
+	SetVariantAxes(ctx context.Context, gearID string, axes []*domain.VariantAxis) error
+	AddGearVariant(ctx context.Context, gearID string, f *domain.AddGearVariantForm) (*domain.GearVariant, error)
+	UpdateGearVariant(ctx context.Context, gearID string, variantID string, f *domain.UpdateGearVariantForm) error
+	DeleteGearVariant(ctx context.Context, gearID string, variantID string) error
+	DecreaseVariantQuantity(ctx context.Context, gearID string, variantID string, quantity int64) (int64, error)
+	GetGearHistory(ctx context.Context, filter domain.ListGearHistoryFilter) (*domain.Page[*domain.GearHistory], error)
+	GetBulkPriceTargets(ctx context.Context, filter domain.ListGearFilter, lock bool) ([]*domain.BulkPriceChange, error)
+	ApplyBulkPriceChanges(ctx context.Context, changes []*domain.BulkPriceChange, undo bool) ([]*domain.BulkPriceChange, error)
+	AddBulkPriceOperation(ctx context.Context, op *domain.BulkPriceOperation) error
+	GetBulkPriceOperation(ctx context.Context, id string) (*domain.BulkPriceOperation, error)
+	SetBulkPriceOperationUndone(ctx context.Context, id string) error
 }
 
 type GearUsecase struct {
-	r GearRepository
+	r  GearRepository
+	cr CategoryRepository
+	tx Transactor
 }
 
-func NewGearUsecase(r GearRepository) *GearUsecase {
+func NewGearUsecase(r GearRepository, cr CategoryRepository, tx Transactor) *GearUsecase {
 	return &GearUsecase{
 		r,
+		cr,
+		tx,
 	}
 }
 
@@ -58,13 +94,52 @@ func (u *GearUsecase) GetGearListCount(ctx context.Context, filter domain.ListGe
 	return result, err
 }
 
-func (u *GearUsecase) GetGearList(ctx context.Context, filter domain.ListGearFilter) ([]*domain.Gear, error) {
+func (u *GearUsecase) GetGearList(ctx context.Context, filter domain.ListGearFilter) (*domain.Page[*domain.Gear], error) {
 	result, err := u.r.GetGearList(ctx, filter)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if filter.WithCount != nil && *filter.WithCount {
+		count, err := u.r.GetGearListCount(ctx, filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.Total = &count
+	}
+
+	return result, err
+}
+
+func (u *GearUsecase) GetGearFacets(ctx context.Context, filter domain.ListGearFilter, buckets []float64) (*domain.GearFacets, error) {
+	result, err := u.r.GetGearFacets(ctx, filter, buckets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+// GetGearSuggestion runs in a transaction holding the similarity threshold
+// of the suggestion query
+func (u *GearUsecase) GetGearSuggestion(ctx context.Context, filter domain.SuggestGearFilter) ([]*domain.GearSuggestion, error) {
+	var result []*domain.GearSuggestion
+
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.r.GetGearSuggestion(ctx, filter)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
 	return result, err
 }
 
@@ -78,32 +153,378 @@ func (u *GearUsecase) GetGearByID(ctx context.Context, id string) (*domain.Gear,
 	return result, err
 }
 
+// validateSpecs checks specs against the attributes of the category key
+func (u *GearUsecase) validateSpecs(ctx context.Context, key string, specs domain.Specs) (domain.Specs, error) {
+	attributes, err := u.cr.GetCategoryAttributes(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ValidateSpecs(attributes, specs)
+}
+
+// CheckCompatibility checks whether the gear of ids work together as one
+// build
+func (u *GearUsecase) CheckCompatibility(ctx context.Context, ids []string) (*domain.CompatibilityReport, error) {
+	gears, err := u.r.GetGearListByIDs(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*domain.Gear, len(gears))
+
+	for _, g := range gears {
+		byID[g.ID] = g
+	}
+
+	parts := buildParts{}
+
+	// ids may come in any form uuid.Parse accepts, like GetGearListByIDs
+	for _, id := range ids {
+		gearUUID, err := uuid.Parse(id)
+
+		if err != nil {
+			return nil, domain.ErrNotFound
+		}
+
+		g, ok := byID[gearUUID]
+
+		if !ok {
+			return nil, domain.ErrNotFound
+		}
+
+		key, err := u.cr.CategoryKeyOf(ctx, g.Type)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parts[key] = append(parts[key], g)
+	}
+
+	return checkCompatibility(parts), nil
+}
+
 func (u *GearUsecase) AddGear(ctx context.Context, f *domain.AddGearForm) error {
-	err := u.r.AddGear(ctx, f)
+	specs, err := u.validateSpecs(ctx, f.Type, f.Specs)
 
 	if err != nil {
 		return err
 	}
 
+	f.Specs = specs
+
+	var uploaded *domain.GearImage
+
+	// the gear and its first image are added together
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		uploaded, err = u.r.AddGear(ctx, f)
+
+		return err
+	})
+
+	if err != nil {
+		u.deleteUploaded(ctx, uploaded)
+
+		return err
+	}
+
 	return nil
 }
 
 func (u *GearUsecase) UpdateGear(ctx context.Context, id string, f *domain.UpdateGearForm) error {
-	err := u.r.UpdateGear(ctx, id, f)
+	// the price, discount and stock of a gear with variants mirror them
+	if f.Price != nil || f.Discount != nil || f.Quantity != nil {
+		gear, err := u.r.GetGearByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if gear.HasVariants() {
+			return fmt.Errorf("%w: set the price, discount and quantity on the variants of the gear", domain.ErrInvalidVariant)
+		}
+	}
+
+	// the specs are checked against the new category when the type changes,
+	// so gear can't keep the specs of its old category
+	if f.Specs != nil || f.Type != nil {
+		gear, err := u.r.GetGearByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		key, err := u.cr.CategoryKeyOf(ctx, gear.Type)
+
+		if err != nil {
+			return err
+		}
+
+		if f.Type != nil {
+			key = *f.Type
+		}
+
+		specs := gear.Specs
+
+		if f.Specs != nil {
+			specs = *f.Specs
+		}
+
+		specs, err = u.validateSpecs(ctx, key, specs)
+
+		if err != nil {
+			return err
+		}
+
+		f.Specs = &specs
+	}
+
+	var uploaded *domain.GearImage
+
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		uploaded, err = u.r.UpdateGear(ctx, id, f)
+
+		return err
+	})
 
 	if err != nil {
+		u.deleteUploaded(ctx, uploaded)
+
 		return err
 	}
 
 	return nil
 }
 
+// DeleteGear archives the gear, past orders keep showing it. See PurgeGear
+// to remove it for good.
 func (u *GearUsecase) DeleteGear(ctx context.Context, id string) error {
-	err := u.r.DeleteGear(ctx, id)
+	return u.r.ArchiveGear(ctx, id)
+}
+
+func (u *GearUsecase) RestoreGear(ctx context.Context, id string) error {
+	return u.r.RestoreGear(ctx, id)
+}
+
+// PurgeGear removes an archived gear that was never ordered, with its
+// images and their stored files
+func (u *GearUsecase) PurgeGear(ctx context.Context, id string) error {
+	var images []*domain.GearImage
+
+	// the gear row stays locked from the checks to the delete, it can't be
+	// restored or ordered in between
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		gear, err := u.r.GetGearForUpdate(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if !gear.Archived() {
+			return domain.ErrGearNotArchived
+		}
+
+		ordered, err := u.r.IsGearOrdered(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if ordered {
+			return domain.ErrGearInUse
+		}
+
+		images, err = u.r.DeleteGear(ctx, id)
+
+		return err
+	})
 
 	if err != nil {
 		return err
 	}
 
+	// the gear is gone either way, files that fail to be deleted are left
+	// for ReconcileImages
+	u.r.DeleteImageObjects(ctx, images)
+
 	return nil
 }
+
+func (u *GearUsecase) GetGearImageList(ctx context.Context, gearID string) ([]*domain.GearImage, error) {
+	result, err := u.r.GetGearImageList(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+// deleteUploaded deletes the files of the images uploaded in a transaction
+// that was rolled back, nothing references them anymore
+func (u *GearUsecase) deleteUploaded(ctx context.Context, images ...*domain.GearImage) {
+	uploaded := []*domain.GearImage{}
+
+	for _, img := range images {
+		if img != nil {
+			uploaded = append(uploaded, img)
+		}
+	}
+
+	if len(uploaded) > 0 {
+		u.r.DeleteImageObjects(context.WithoutCancel(ctx), uploaded)
+	}
+}
+
+func (u *GearUsecase) AddGearImage(ctx context.Context, gearID string, f *domain.NewGearImage) (*domain.GearImage, error) {
+	_, err := u.r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result *domain.GearImage
+	var uploaded *domain.GearImage
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		image, err := u.r.AddGearImage(ctx, gearID, f)
+
+		if err != nil {
+			return err
+		}
+
+		uploaded = image
+
+		if f.Primary && !image.Primary {
+			err = u.r.SetPrimaryGearImage(ctx, gearID, image.ID.String())
+
+			if err != nil {
+				return err
+			}
+
+			image.Primary = true
+		}
+
+		result = image
+
+		return nil
+	})
+
+	if err != nil {
+		u.deleteUploaded(ctx, uploaded)
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getGearImage returns the image imageID of the gear
+func (u *GearUsecase) getGearImage(ctx context.Context, gearID string, imageID string) (*domain.GearImage, error) {
+	images, err := u.r.GetGearImageList(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	imageUUID, err := uuid.Parse(imageID)
+
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	for _, img := range images {
+		if img.ID == imageUUID {
+			return img, nil
+		}
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (u *GearUsecase) UpdateGearImage(ctx context.Context, gearID string, imageID string, f *domain.UpdateGearImageForm) error {
+	image, err := u.getGearImage(ctx, gearID, imageID)
+
+	if err != nil {
+		return err
+	}
+
+	return u.r.UpdateGearImage(ctx, image.ID.String(), f)
+}
+
+func (u *GearUsecase) SetPrimaryGearImage(ctx context.Context, gearID string, imageID string) error {
+	return u.r.SetPrimaryGearImage(ctx, gearID, imageID)
+}
+
+// ReorderGearImages orders the gallery as imageIDs, which must hold every
+// image of the gear exactly once
+func (u *GearUsecase) ReorderGearImages(ctx context.Context, gearID string, imageIDs []string) error {
+	images, err := u.r.GetGearImageList(ctx, gearID)
+
+	if err != nil {
+		return err
+	}
+
+	seen := map[uuid.UUID]bool{}
+	// the ids are sent on in their canonical form
+	ordered := make([]string, len(imageIDs))
+
+	for i, id := range imageIDs {
+		imageUUID, err := uuid.Parse(id)
+
+		if err != nil {
+			return domain.ErrInvalidImageOrder
+		}
+
+		seen[imageUUID] = true
+		ordered[i] = imageUUID.String()
+	}
+
+	if len(seen) != len(imageIDs) || len(imageIDs) != len(images) {
+		return domain.ErrInvalidImageOrder
+	}
+
+	for _, img := range images {
+		if !seen[img.ID] {
+			return domain.ErrInvalidImageOrder
+		}
+	}
+
+	return u.r.ReorderGearImages(ctx, gearID, ordered)
+}
+
+func (u *GearUsecase) DeleteGearImage(ctx context.Context, gearID string, imageID string) error {
+	image, err := u.r.DeleteGearImage(ctx, gearID, imageID)
+
+	if err != nil {
+		return err
+	}
+
+	// files that fail to be deleted are left for ReconcileImages
+	u.r.DeleteImageObjects(ctx, []*domain.GearImage{image})
+
+	return nil
+}
+
+// ReconcileImages deletes the stored images no gear references, or only
+// reports them when dryRun is set
+func (u *GearUsecase) ReconcileImages(ctx context.Context, dryRun bool) (*domain.ImageReconcileReport, error) {
+	return u.r.ReconcileImages(ctx, dryRun)
+}
+
+// GetGearHistory lists the price, discount and stock changes of the gear,
+// newest first
+func (u *GearUsecase) GetGearHistory(ctx context.Context, filter domain.ListGearHistoryFilter) (*domain.Page[*domain.GearHistory], error) {
+	_, err := u.r.GetGearByID(ctx, filter.GearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetGearHistory(ctx, filter)
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+)
+
+// withReason sets the reason recorded in the gear history, unless the
+// caller already gave one
+func withReason(ctx context.Context, reason string) context.Context {
+	change := domain.GearChange{}
+
+	if c := domain.GearChangeFrom(ctx); c != nil {
+		change = *c
+	}
+
+	if change.Reason == "" {
+		change.Reason = reason
+	}
+
+	return domain.WithGearChange(ctx, &change)
+}
+
+// bulkPriceChanges applies rule to the gear matching the filter and returns
+// the changes that change something
+func (u *GearUsecase) bulkPriceChanges(ctx context.Context, filter domain.ListGearFilter, rule *domain.BulkPriceRule, lock bool) ([]*domain.BulkPriceChange, error) {
+	if rule.Empty() {
+		return nil, domain.ErrEmptyBulkRule
+	}
+
+	targets, err := u.r.GetBulkPriceTargets(ctx, filter, lock)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*domain.BulkPriceChange{}
+
+	for _, c := range targets {
+		c.Price, c.Discount = rule.Apply(c.OldPrice, c.OldDiscount)
+
+		if c.Price != c.OldPrice || c.Discount != c.OldDiscount {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+// PreviewBulkPrice returns the changes ExecuteBulkPrice would make now
+func (u *GearUsecase) PreviewBulkPrice(ctx context.Context, filter domain.ListGearFilter, rule *domain.BulkPriceRule) ([]*domain.BulkPriceChange, error) {
+	return u.bulkPriceChanges(ctx, filter, rule, false)
+}
+
+// ExecuteBulkPrice applies rule to the gear matching the filter, or to
+// their variants, in one transaction and records the previous values so the
+// operation can be undone
+func (u *GearUsecase) ExecuteBulkPrice(ctx context.Context, filter domain.ListGearFilter, rule *domain.BulkPriceRule) (*domain.BulkPriceOperation, error) {
+	id, err := uuid.NewV7()
+
+	if err != nil {
+		return nil, err
+	}
+
+	op := &domain.BulkPriceOperation{
+		ID:   id,
+		Rule: rule,
+	}
+
+	if change := domain.GearChangeFrom(ctx); change != nil {
+		op.ActorID = change.ActorID
+	}
+
+	ctx = withReason(ctx, fmt.Sprintf("bulk price %v", id))
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		changes, err := u.bulkPriceChanges(ctx, filter, rule, true)
+
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return fmt.Errorf("%w: no gear matching the filter would change", domain.ErrEmptyBulkRule)
+		}
+
+		// the gear and variant rows are locked, nothing can have changed since
+		applied, err := u.r.ApplyBulkPriceChanges(ctx, changes, false)
+
+		if err != nil {
+			return err
+		}
+
+		if len(applied) != len(changes) {
+			return errors.New("gear changed during the operation, try again")
+		}
+
+		op.Changes = changes
+
+		return u.r.AddBulkPriceOperation(ctx, op)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetBulkPriceOperation(ctx, id.String())
+}
+
+func (u *GearUsecase) GetBulkPriceOperation(ctx context.Context, id string) (*domain.BulkPriceOperation, error) {
+	return u.r.GetBulkPriceOperation(ctx, id)
+}
+
+// UndoBulkPrice reverts the gear changed by the operation to their previous
+// values. A gear whose price or discount changed again since is skipped.
+func (u *GearUsecase) UndoBulkPrice(ctx context.Context, id string) (*domain.BulkPriceUndoReport, error) {
+	op, err := u.r.GetBulkPriceOperation(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.BulkPriceUndoReport{
+		Skipped: []*domain.BulkPriceChange{},
+	}
+
+	ctx = withReason(ctx, fmt.Sprintf("undo bulk price %v", op.ID))
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// marking it first keeps a concurrent undo out
+		err := u.r.SetBulkPriceOperationUndone(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		applied, err := u.r.ApplyBulkPriceChanges(ctx, op.Changes, true)
+
+		if err != nil {
+			return err
+		}
+
+		reverted := make(map[*domain.BulkPriceChange]bool, len(applied))
+
+		for _, c := range applied {
+			reverted[c] = true
+		}
+
+		for _, c := range op.Changes {
+			if !reverted[c] {
+				report.Skipped = append(report.Skipped, c)
+			}
+		}
+
+		report.Reverted = len(applied)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
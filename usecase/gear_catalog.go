@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+// exportPageSize is the number of gear read at once by ExportGear
+const exportPageSize = 100
+
+// importUpdateForm turns an import row into the update of the existing gear.
+// The gallery of existing gear is left alone, and so are the price, discount
+// and stock of gear with variants since they mirror the variants.
+func importUpdateForm(gear *domain.Gear, f *domain.AddGearForm) (*domain.UpdateGearForm, error) {
+	form := &domain.UpdateGearForm{
+		Name:    &f.Name,
+		Type:    &f.Type,
+		Brand:   &f.Brand,
+		Variety: &f.Variety,
+		Specs:   &f.Specs,
+	}
+
+	if !gear.HasVariants() {
+		form.Price = &f.Price
+		form.Discount = &f.Discount
+		form.Quantity = &f.Quantity
+
+		return form, nil
+	}
+
+	if f.Price != gear.Price || f.Discount != gear.Discount || f.Quantity != gear.Quantity {
+		return nil, fmt.Errorf("%w: set the price, discount and quantity on the variants of the gear", domain.ErrInvalidVariant)
+	}
+
+	return form, nil
+}
+
+// ImportGear adds the gear of rows, or updates the gear having the same SKU.
+// Nothing is written when a row has an error or with dryRun, the report
+// tells what would have been done.
+func (u *GearUsecase) ImportGear(ctx context.Context, rows []*domain.GearImportRow, dryRun bool) (*domain.GearImportReport, error) {
+	report := &domain.GearImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []*domain.GearImportError{},
+	}
+
+	fail := func(row *domain.GearImportRow, err error) {
+		e := &domain.GearImportError{
+			Line:    row.Line,
+			Message: err.Error(),
+		}
+
+		if row.Form != nil && row.Form.SKU != nil {
+			e.SKU = *row.Form.SKU
+		}
+
+		report.Errors = append(report.Errors, e)
+	}
+
+	skus := []string{}
+	lines := map[string]int{}
+
+	for _, row := range rows {
+		if row.Err != nil {
+			fail(row, row.Err)
+			continue
+		}
+
+		sku := *row.Form.SKU
+
+		if line, ok := lines[sku]; ok {
+			fail(row, fmt.Errorf("sku is also on line %v", line))
+			continue
+		}
+
+		lines[sku] = row.Line
+		skus = append(skus, sku)
+	}
+
+	gears, err := u.r.GetGearListBySKUs(ctx, skus)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*domain.Gear, len(gears))
+
+	for _, g := range gears {
+		existing[g.SKU] = g
+	}
+
+	updates := map[*domain.GearImportRow]*domain.UpdateGearForm{}
+	valid := []*domain.GearImportRow{}
+
+	for _, row := range rows {
+		if row.Err != nil || lines[*row.Form.SKU] != row.Line {
+			continue
+		}
+
+		specs, err := u.validateSpecs(ctx, row.Form.Type, row.Form.Specs)
+
+		if err != nil {
+			fail(row, err)
+			continue
+		}
+
+		row.Form.Specs = specs
+		gear, ok := existing[*row.Form.SKU]
+
+		if !ok {
+			report.Created++
+			valid = append(valid, row)
+			continue
+		}
+
+		if gear.Archived() {
+			fail(row, domain.ErrGearArchived)
+			continue
+		}
+
+		form, err := importUpdateForm(gear, row.Form)
+
+		if err != nil {
+			fail(row, err)
+			continue
+		}
+
+		updates[row] = form
+		report.Updated++
+		valid = append(valid, row)
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	// the images uploaded by rows written before a failing one are deleted
+	// with the rollback
+	uploaded := []*domain.GearImage{}
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, row := range valid {
+			var image *domain.GearImage
+			var err error
+
+			if form, ok := updates[row]; ok {
+				image, err = u.r.UpdateGear(ctx, existing[*row.Form.SKU].ID.String(), form)
+			} else {
+				image, err = u.r.AddGear(ctx, row.Form)
+			}
+
+			uploaded = append(uploaded, image)
+
+			if err != nil {
+				return fmt.Errorf("line %v: %w", row.Line, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		u.deleteUploaded(ctx, uploaded...)
+
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ExportGear calls fn with every gear matching the filter, as the form
+// importing it back. Page, Limit and Cursor of the filter are ignored.
+func (u *GearUsecase) ExportGear(ctx context.Context, filter domain.ListGearFilter, fn func(f *domain.AddGearForm) error) error {
+	if filter.Category == nil {
+		all := domain.AllCategory
+		filter.Category = &all
+	}
+
+	page := int64(1)
+	limit := int64(exportPageSize)
+
+	filter.Page = &page
+	filter.Limit = &limit
+	filter.Cursor = nil
+
+	// gear is imported with the key of its category, it is stored with its
+	// code
+	keys := map[string]string{}
+
+	for {
+		result, err := u.r.GetGearList(ctx, filter)
+
+		if err != nil {
+			return err
+		}
+
+		for _, g := range result.Items {
+			key, ok := keys[g.Type]
+
+			if !ok {
+				key, err = u.cr.CategoryKeyOf(ctx, g.Type)
+
+				if err != nil {
+					return err
+				}
+
+				keys[g.Type] = key
+			}
+
+			err = fn(&domain.AddGearForm{
+				SKU:      &g.SKU,
+				Name:     g.Name,
+				Type:     key,
+				Brand:    g.Brand,
+				Variety:  g.Variety,
+				Price:    g.Price,
+				Discount: g.Discount,
+				Quantity: g.Quantity,
+				Specs:    g.Specs,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if result.NextCursor == nil {
+			return nil
+		}
+
+		filter.Cursor = result.NextCursor
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/goldenfealla/gear-manager/domain"
+)
+
+func (u *GearUsecase) GetGearVariantList(ctx context.Context, gearID string) ([]*domain.GearVariant, error) {
+	_, err := u.r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetGearVariantList(ctx, gearID)
+}
+
+// SetVariantAxes sets the options the gear is sold in, they can only change
+// while the gear has no variant
+func (u *GearUsecase) SetVariantAxes(ctx context.Context, gearID string, f *domain.SetVariantAxesForm) error {
+	gear, err := u.r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return err
+	}
+
+	if len(gear.Variants) > 0 {
+		return fmt.Errorf("%w: variant axes can't change while the gear has variants", domain.ErrInvalidVariant)
+	}
+
+	keys := []string{}
+
+	for _, a := range f.Axes {
+		if slices.Contains(keys, a.Key) {
+			return fmt.Errorf("%w: axis '%v' is given twice", domain.ErrInvalidVariant, a.Key)
+		}
+
+		keys = append(keys, a.Key)
+	}
+
+	return u.r.SetVariantAxes(ctx, gearID, f.Axes)
+}
+
+// checkVariant checks the options and the image of a variant of gear,
+// except is the variant being updated
+func checkVariant(gear *domain.Gear, except *domain.GearVariant, options map[string]string, imageID *string) error {
+	if options != nil {
+		err := domain.ValidateVariantOptions(gear.VariantAxes, options)
+
+		if err != nil {
+			return err
+		}
+
+		for _, v := range gear.Variants {
+			if except != nil && v.ID == except.ID {
+				continue
+			}
+
+			if domain.SameOptions(v.Options, options) {
+				return fmt.Errorf("%w: variant %v has the same options", domain.ErrInvalidVariant, v.SKU)
+			}
+		}
+	}
+
+	if imageID == nil || *imageID == "" {
+		return nil
+	}
+
+	for _, img := range gear.Images {
+		if img.ID.String() == *imageID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: image_id is not an image of the gear", domain.ErrInvalidVariant)
+}
+
+func (u *GearUsecase) AddGearVariant(ctx context.Context, gearID string, f *domain.AddGearVariantForm) (*domain.GearVariant, error) {
+	gear, err := u.r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !gear.HasVariants() {
+		return nil, fmt.Errorf("%w: set the variant axes of the gear first", domain.ErrInvalidVariant)
+	}
+
+	err = checkVariant(gear, nil, f.Options, f.ImageID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result *domain.GearVariant
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		result, err = u.r.AddGearVariant(ctx, gearID, f)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *GearUsecase) UpdateGearVariant(ctx context.Context, gearID string, variantID string, f *domain.UpdateGearVariantForm) error {
+	gear, err := u.r.GetGearByID(ctx, gearID)
+
+	if err != nil {
+		return err
+	}
+
+	variant, err := u.r.GetGearVariant(ctx, gearID, variantID)
+
+	if err != nil {
+		return err
+	}
+
+	var options map[string]string
+
+	if f.Options != nil {
+		options = *f.Options
+	}
+
+	err = checkVariant(gear, variant, options, f.ImageID)
+
+	if err != nil {
+		return err
+	}
+
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.r.UpdateGearVariant(ctx, gearID, variantID, f)
+	})
+}
+
+// DeleteGearVariant fails with domain.ErrVariantInUse once the variant has
+// been ordered
+func (u *GearUsecase) DeleteGearVariant(ctx context.Context, gearID string, variantID string) error {
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.r.DeleteGearVariant(ctx, gearID, variantID)
+	})
+}
@@ -3,6 +3,10 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/goldenfealla/gear-manager/domain"
 )
@@ -11,12 +15,13 @@ type OrderRepository interface {
 	HasCart(ctx context.Context, userID string) bool
 	GetFullCartByUserID(ctx context.Context, userID string) (*domain.FullOrder, error)
 	GetFullOrderByID(ctx context.Context, orderID string) (*domain.FullOrder, error)
-	GetFullOrderList(ctx context.Context, userID string, page int64, limit int64) ([]*domain.Order, error)
+	GetOrderForUpdate(ctx context.Context, orderID string) (*domain.Order, error)
+	GetFullOrderList(ctx context.Context, userID string, cursor *string, page int64, limit int64) (*domain.Page[*domain.Order], error)
 	GetCartInfo(ctx context.Context, userID string) (*domain.Order, error)
 	CreateCart(ctx context.Context, userID string) error
-	AddProductToCart(ctx context.Context, cart *domain.Order, gearID string) error
-	SetGearQuantityCart(ctx context.Context, cart *domain.Order, gearID string, quantity int64) error
-	RemoveProductToCart(ctx context.Context, cart *domain.Order, gearID string) error
+	AddProductToCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string) error
+	SetGearQuantityCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string, quantity int64) error
+	RemoveProductToCart(ctx context.Context, cart *domain.Order, gearID string, variantID *string) error
 	UpdateOrderStatus(ctx context.Context, cartID string, status domain.OrderStatus) error
 	UpdateOrderTotalPrice(ctx context.Context, cartID string, price int64) error
 }
@@ -25,13 +30,15 @@ type OrderUsercase struct {
 	or OrderRepository
 	ur UserRepository
 	gr GearRepository
+	tx Transactor
 }
 
-func NewOrderUsercase(or OrderRepository, ur UserRepository, gr GearRepository) *OrderUsercase {
+func NewOrderUsercase(or OrderRepository, ur UserRepository, gr GearRepository, tx Transactor) *OrderUsercase {
 	return &OrderUsercase{
 		or,
 		ur,
 		gr,
+		tx,
 	}
 }
 
@@ -58,7 +65,39 @@ func (u *OrderUsercase) GetCart(ctx context.Context, userID string) (*domain.Ful
 	return cart, nil
 }
 
-func (u *OrderUsercase) AddGearToCart(ctx context.Context, userID string, gearID string) error {
+// cartVariant returns variantID as the variant of a cart line, or nil when
+// it is empty
+func cartVariant(variantID string) *string {
+	if variantID == "" {
+		return nil
+	}
+
+	return &variantID
+}
+
+// AddGearToCart adds the gear to the cart, a gear with variants is added
+// through one of them
+func (u *OrderUsercase) AddGearToCart(ctx context.Context, userID string, gearID string, variantID string) error {
+	gear, err := u.gr.GetGearByID(ctx, gearID)
+	if err != nil {
+		return err
+	}
+
+	if gear.Archived() {
+		return domain.ErrGearArchived
+	}
+
+	if variantID == "" && gear.HasVariants() {
+		return domain.ErrVariantRequired
+	}
+
+	if variantID != "" {
+		_, err := u.gr.GetGearVariant(ctx, gearID, variantID)
+		if err != nil {
+			return err
+		}
+	}
+
 	if !u.or.HasCart(ctx, userID) {
 		u.or.CreateCart(ctx, userID)
 	}
@@ -68,7 +107,7 @@ func (u *OrderUsercase) AddGearToCart(ctx context.Context, userID string, gearID
 		return err
 	}
 
-	err = u.or.AddProductToCart(ctx, cart, gearID)
+	err = u.or.AddProductToCart(ctx, cart, gearID, cartVariant(variantID))
 	if err != nil {
 		return err
 	}
@@ -76,9 +115,9 @@ func (u *OrderUsercase) AddGearToCart(ctx context.Context, userID string, gearID
 	return nil
 }
 
-func (u *OrderUsercase) SetGearQuantityCart(ctx context.Context, userID string, gearID string, quantity int64) error {
+func (u *OrderUsercase) SetGearQuantityCart(ctx context.Context, userID string, gearID string, variantID string, quantity int64) error {
 	if quantity <= 0 {
-		return errors.New("quantity must be bigger than 0")
+		return &domain.FilterError{Param: "quantity", Message: "must be bigger than 0"}
 	}
 
 	cart, err := u.or.GetCartInfo(ctx, userID)
@@ -86,7 +125,7 @@ func (u *OrderUsercase) SetGearQuantityCart(ctx context.Context, userID string,
 		return err
 	}
 
-	err = u.or.SetGearQuantityCart(ctx, cart, gearID, quantity)
+	err = u.or.SetGearQuantityCart(ctx, cart, gearID, cartVariant(variantID), quantity)
 	if err != nil {
 		return err
 	}
@@ -95,7 +134,7 @@ func (u *OrderUsercase) SetGearQuantityCart(ctx context.Context, userID string,
 
 }
 
-func (u *OrderUsercase) RemoveGearFromCart(ctx context.Context, userID string, gearID string) error {
+func (u *OrderUsercase) RemoveGearFromCart(ctx context.Context, userID string, gearID string, variantID string) error {
 	if !u.or.HasCart(ctx, userID) {
 		u.or.CreateCart(ctx, userID)
 	}
@@ -105,7 +144,7 @@ func (u *OrderUsercase) RemoveGearFromCart(ctx context.Context, userID string, g
 		return err
 	}
 
-	err = u.or.RemoveProductToCart(ctx, cart, gearID)
+	err = u.or.RemoveProductToCart(ctx, cart, gearID, cartVariant(variantID))
 	if err != nil {
 		return err
 	}
@@ -113,65 +152,148 @@ func (u *OrderUsercase) RemoveGearFromCart(ctx context.Context, userID string, g
 	return nil
 }
 
-func (u *OrderUsercase) updateGearQuantityWorker(
-	ctx context.Context,
-	ordergear <-chan *domain.OrderGear,
-	pricegear chan<- int64,
-) {
-	for og := range ordergear {
-		r := og.Gear.Quantity - og.Quantity
-		u.gr.UpdateGearQuantity(ctx, og.Gear.ID.String(), r)
-		pricegear <- int64(og.Gear.Price) * og.Quantity
-	}
+// PayCart takes the stock of every line and marks the order as PAID in a
+// single transaction. The order row is locked first so the same cart can't be
+// paid twice, and each line is decremented only if enough stock is left.
+// When any line runs short nothing is committed and the returned error lists
+// every line that failed.
+func (u *OrderUsercase) PayCart(ctx context.Context, user *domain.UserInfo, orderID string) error {
+	ctx = domain.WithGearChange(ctx, &domain.GearChange{
+		ActorID: &user.ID,
+		Reason:  fmt.Sprintf("order %v", orderID),
+	})
+
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := u.or.GetOrderForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(user, order.UserID, domain.PermissionManageOrder)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != domain.CART {
+			return domain.ErrOrderNotCart
+		}
+
+		cart, err := u.or.GetFullOrderByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if len(cart.OrderGear) == 0 {
+			return domain.ErrCartEmpty
+		}
+
+		archivedErrs := []error{}
+
+		for _, og := range cart.OrderGear {
+			if og.Gear.Archived() {
+				archivedErrs = append(archivedErrs, fmt.Errorf("%w: %v", domain.ErrGearArchived, og.Gear.Name))
+			}
+		}
+
+		if len(archivedErrs) > 0 {
+			return errors.Join(archivedErrs...)
+		}
+
+		// always lock gear and variant rows in the same order to avoid
+		// deadlocks between concurrent checkouts
+		lines := slices.Clone(cart.OrderGear)
+		slices.SortFunc(lines, func(a, b *domain.OrderGear) int {
+			if c := strings.Compare(a.Gear.ID.String(), b.Gear.ID.String()); c != 0 {
+				return c
+			}
+
+			return strings.Compare(lineVariantID(a), lineVariantID(b))
+		})
+
+		totalPrice := int64(0)
+		stockErrs := []error{}
+
+		for _, og := range lines {
+			name := og.Gear.Name
+
+			// the stock seen under the row lock, the cart read before it
+			// may be stale by now
+			var available int64
+
+			// the gear got variants after the line was added, its own
+			// stock only mirrors theirs
+			if og.Variant == nil && og.Gear.HasVariants() {
+				return fmt.Errorf("%w: %v", domain.ErrVariantRequired, name)
+			}
+
+			if og.Variant != nil {
+				name = fmt.Sprintf("%v (%v)", og.Gear.Name, og.Variant.SKU)
+				available, err = u.gr.DecreaseVariantQuantity(ctx, og.Gear.ID.String(), og.Variant.ID.String(), og.Quantity)
+			} else {
+				available, err = u.gr.DecreaseGearQuantity(ctx, og.Gear.ID.String(), og.Quantity)
+			}
+
+			if errors.Is(err, domain.ErrInsufficientStock) {
+				stockErrs = append(stockErrs, fmt.Errorf(
+					"%w for %v: requested %v, available %v",
+					domain.ErrInsufficientStock,
+					name,
+					og.Quantity,
+					available,
+				))
+				continue
+			}
+
+			if errors.Is(err, domain.ErrVariantRequired) {
+				return fmt.Errorf("%w: %v", err, name)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			totalPrice += int64(math.Round(og.UnitPrice() * float64(og.Quantity)))
+		}
+
+		if len(stockErrs) > 0 {
+			return errors.Join(stockErrs...)
+		}
+
+		err = u.or.UpdateOrderTotalPrice(ctx, orderID, totalPrice)
+		if err != nil {
+			return err
+		}
+
+		return u.or.UpdateOrderStatus(ctx, orderID, domain.PAID)
+	})
 }
 
-func (u *OrderUsercase) PayCart(ctx context.Context, orderID string) error {
-	cart, err := u.or.GetFullOrderByID(ctx, orderID)
-	if err != nil {
-		return err
-	}
-
-	err = u.or.UpdateOrderStatus(ctx, cart.Order.ID.String(), domain.PAID)
-	if err != nil {
-		return err
+// lineVariantID is the variant id of a line, empty for a gear without
+// variants
+func lineVariantID(og *domain.OrderGear) string {
+	if og.Variant == nil {
+		return ""
 	}
 
-	numOfOrderGear := len(cart.OrderGear)
-	orderGearsChan := make(chan *domain.OrderGear, numOfOrderGear)
-	priceGearsChan := make(chan int64, numOfOrderGear)
-	// 3 worker
-	for w := 1; w <= 3; w++ {
-		go u.updateGearQuantityWorker(ctx, orderGearsChan, priceGearsChan)
-	}
-
-	for j := 0; j < numOfOrderGear; j++ {
-		orderGearsChan <- cart.OrderGear[j]
-	}
-	close(orderGearsChan)
-
-	totalPrice := int64(0)
-
-	for j := 0; j < numOfOrderGear; j++ {
-		priceGear := <-priceGearsChan
-		totalPrice += priceGear
-	}
-
-	u.or.UpdateOrderTotalPrice(ctx, cart.Order.ID.String(), totalPrice)
-
-	return nil
+	return og.Variant.ID.String()
 }
 
-func (u *OrderUsercase) GetOrder(ctx context.Context, id string) (*domain.FullOrder, error) {
+func (u *OrderUsercase) GetOrder(ctx context.Context, user *domain.UserInfo, id string) (*domain.FullOrder, error) {
 	order, err := u.or.GetFullOrderByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	err = authorize(user, order.Order.UserID, domain.PermissionManageOrder)
+	if err != nil {
+		return nil, err
+	}
+
 	return order, nil
 }
 
-func (u *OrderUsercase) GetOrderList(ctx context.Context, userID string, page int64, limit int64) ([]*domain.Order, error) {
-	orders, err := u.or.GetFullOrderList(ctx, userID, page, limit)
+func (u *OrderUsercase) GetOrderList(ctx context.Context, userID string, cursor *string, page int64, limit int64) (*domain.Page[*domain.Order], error) {
+	orders, err := u.or.GetFullOrderList(ctx, userID, cursor, page, limit)
 	if err != nil {
 		return nil, err
 	}
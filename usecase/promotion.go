@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goldenfealla/gear-manager/domain"
+	"github.com/google/uuid"
+)
+
+type PromotionRepository interface {
+	GetPromotionList(ctx context.Context, status domain.PromotionStatus) ([]*domain.Promotion, error)
+	GetPromotionByID(ctx context.Context, id string) (*domain.Promotion, error)
+	AddPromotion(ctx context.Context, f *domain.AddPromotionForm) (uuid.UUID, error)
+	UpdatePromotion(ctx context.Context, id string, f *domain.UpdatePromotionForm) error
+	DeletePromotion(ctx context.Context, id string) error
+}
+
+type PromotionUsecase struct {
+	r  PromotionRepository
+	gr GearRepository
+	cr CategoryRepository
+}
+
+func NewPromotionUsecase(r PromotionRepository, gr GearRepository, cr CategoryRepository) *PromotionUsecase {
+	return &PromotionUsecase{
+		r,
+		gr,
+		cr,
+	}
+}
+
+// checkTargets normalizes the brands and category keys the promotion
+// targets and checks the gear and categories exist
+func (u *PromotionUsecase) checkTargets(ctx context.Context, gearIDs []string, brands []string, categories []string) error {
+	if len(gearIDs)+len(brands)+len(categories) == 0 {
+		return fmt.Errorf("%w: it must target gear, a brand or a category", domain.ErrInvalidPromotion)
+	}
+
+	if len(gearIDs) > 0 {
+		_, err := u.gr.GetGearListByIDs(ctx, gearIDs)
+
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: gear not found", domain.ErrInvalidPromotion)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, b := range brands {
+		brands[i] = strings.TrimSpace(b)
+	}
+
+	for i, key := range categories {
+		key = strings.ToLower(strings.TrimSpace(key))
+		categories[i] = key
+
+		_, err := u.cr.GetCategoryCodes(ctx, key)
+
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: category %v not exist", domain.ErrInvalidPromotion, key)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPromotionList lists the promotions of status, every promotion when
+// status is empty
+func (u *PromotionUsecase) GetPromotionList(ctx context.Context, status domain.PromotionStatus) ([]*domain.Promotion, error) {
+	switch status {
+	case "", domain.PromotionScheduled, domain.PromotionRunning, domain.PromotionEnded:
+	default:
+		return nil, &domain.FilterError{Param: "status", Message: "must be scheduled, running or ended"}
+	}
+
+	return u.r.GetPromotionList(ctx, status)
+}
+
+func (u *PromotionUsecase) GetPromotionByID(ctx context.Context, id string) (*domain.Promotion, error) {
+	return u.r.GetPromotionByID(ctx, id)
+}
+
+func (u *PromotionUsecase) AddPromotion(ctx context.Context, f *domain.AddPromotionForm) (*domain.Promotion, error) {
+	err := u.checkTargets(ctx, f.GearIDs, f.Brands, f.Categories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := u.r.AddPromotion(ctx, f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.r.GetPromotionByID(ctx, id.String())
+}
+
+// UpdatePromotion changes the promotion, a running promotion can be cut
+// short by moving its end
+func (u *PromotionUsecase) UpdatePromotion(ctx context.Context, id string, f *domain.UpdatePromotionForm) error {
+	p, err := u.r.GetPromotionByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	startsAt, endsAt := p.StartsAt, p.EndsAt
+
+	if f.StartsAt != nil {
+		startsAt = *f.StartsAt
+	}
+
+	if f.EndsAt != nil {
+		endsAt = *f.EndsAt
+	}
+
+	if !startsAt.Before(endsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidPromotion)
+	}
+
+	if f.GearIDs != nil || f.Brands != nil || f.Categories != nil {
+		gearIDs := make([]string, len(p.GearIDs))
+
+		for i, id := range p.GearIDs {
+			gearIDs[i] = id.String()
+		}
+
+		brands, categories := p.Brands, p.Categories
+
+		if f.GearIDs != nil {
+			gearIDs = *f.GearIDs
+		}
+
+		if f.Brands != nil {
+			brands = *f.Brands
+		}
+
+		if f.Categories != nil {
+			categories = *f.Categories
+		}
+
+		err = u.checkTargets(ctx, gearIDs, brands, categories)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.r.UpdatePromotion(ctx, id, f)
+}
+
+func (u *PromotionUsecase) DeletePromotion(ctx context.Context, id string) error {
+	return u.r.DeletePromotion(ctx, id)
+}
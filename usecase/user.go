@@ -18,6 +18,7 @@ type UserRepository interface {
 	GetByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*domain.User, error)
 	AddUser(ctx context.Context, user *domain.User) error
 	UpdateUser(ctx context.Context, id string, user *domain.UpdateUserForm) error
+	UpdateUserRole(ctx context.Context, id string, role domain.Role) error
 }
 
 type UserUsecase struct {
@@ -64,6 +65,7 @@ func (u *UserUsecase) RegisterUser(ctx context.Context, f *domain.RegisterUserFo
 		LastName:  f.LastName,
 		Phone:     f.Phone,
 		Password:  hashedPassword,
+		Role:      domain.CUSTOMER,
 	}
 
 	err = u.r.AddUser(ctx, user)
@@ -79,6 +81,9 @@ func (u *UserUsecase) RegisterUser(ctx context.Context, f *domain.RegisterUserFo
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Phone:     user.Phone,
+		Role:      user.Role,
+
+		SessionVersion: user.SessionVersion,
 	}, nil
 }
 
@@ -112,6 +117,9 @@ func (u *UserUsecase) LoginUser(ctx context.Context, f *domain.LoginUserForm) (*
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Phone:     user.Phone,
+		Role:      user.Role,
+
+		SessionVersion: user.SessionVersion,
 	}, nil
 }
 
@@ -145,5 +153,52 @@ func (u *UserUsecase) UpdateUser(ctx context.Context, id string, f *domain.Updat
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Phone:     user.Phone,
+		Role:      user.Role,
+
+		SessionVersion: user.SessionVersion,
+	}, nil
+}
+
+func (u *UserUsecase) SetUserRole(ctx context.Context, id string, role domain.Role) (*domain.UserInfo, error) {
+	if _, ok := domain.RolePermissionMap[role]; !ok {
+		return nil, &domain.FilterError{Param: "role", Message: "must be CUSTOMER, STAFF or ADMIN"}
+	}
+
+	if err := uuid.Validate(id); err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	existedUser, err := u.r.CheckIDExist(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !existedUser {
+		return nil, fmt.Errorf("%w: user not existed", domain.ErrNotFound)
+	}
+
+	err = u.r.UpdateUserRole(ctx, id, role)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.r.GetUserByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserInfo{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Phone:     user.Phone,
+		Role:      user.Role,
+
+		SessionVersion: user.SessionVersion,
 	}, nil
 }